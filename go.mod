@@ -4,7 +4,6 @@ go 1.16
 
 require (
 	github.com/Shopify/sarama v1.29.1
-	github.com/r3labs/diff v1.1.0
 	github.com/rs/zerolog v1.23.0
 	github.com/spf13/cobra v1.2.1
 	github.com/stretchr/testify v1.7.0
@@ -12,4 +11,5 @@ require (
 	k8s.io/api v0.21.2
 	k8s.io/apimachinery v0.21.2
 	k8s.io/client-go v0.21.2
+	sigs.k8s.io/yaml v1.2.0
 )
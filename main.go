@@ -8,14 +8,23 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"reflect"
 	"regexp"
+	"sync"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/j2gg0s/kubenotify/pkg/client"
+	"github.com/j2gg0s/kubenotify/pkg/config"
+	"github.com/j2gg0s/kubenotify/pkg/diff"
 	"github.com/j2gg0s/kubenotify/pkg/notify"
 	"github.com/j2gg0s/kubenotify/pkg/sentry"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 )
@@ -28,35 +37,85 @@ var (
 	excludes = []string{
 		`metadata\.[acdfgmors].*`,
 		`status\..*`,
-		`spec\.template\.spec\.containers\.[123456789]`,
 		// jaeger injected
 		`metadata\.labels\.sidecar\.jaegertracing\.io\/injected`,
 	}
 	includes          = []string{}
+	redacts           = diff.DefaultRedactPaths
+	redactFlags       = diff.DefaultRedactFlags
 	webhooks          = []string{}
+	slackWebhooks     = []string{}
+	larkWebhooks      = []string{}
+	dingtalkWebhooks  = []string{}
+	wecomWebhooks     = []string{}
+	tmpl              = ""
+	tmplFile          = ""
+	diffStyle         = "inline"
+	configFile        = ""
+	configReload      = "10s"
 	ignoreBefore      = "1m"
 	includeResources  = []string{}
+	customResources   = []string{}
 	includeNamespaces = []string{}
 	resync            = "1m"
+	rolloutDeadline   = "10m"
+	imageLink         = ""
 	disableRevision   = true
+	ignoreScaling     = false
+	restartThreshold  = int32(5)
+	logLines          = int64(0)
+	logBytes          = int64(4096)
+	logTimeout        = "5s"
+
+	kafkaBrokers       = []string{}
+	kafkaTopic         = "kubenotify"
+	kafkaSASLUser      = ""
+	kafkaSASLPassword  = ""
+	kafkaTLS           = false
+	kafkaTLSSkipVerify = false
 )
 
 func main() {
 	root := cobra.Command{
 		Use:  "kubenotify",
-		Long: "subscribe kubernetes workload change event, support Deployment, StatefulSet and DaemonSet",
+		Long: "subscribe kubernetes workload change event, support Deployment, StatefulSet, DaemonSet, CronJob and Job",
 	}
 
 	root.PersistentFlags().BoolVar(&debug, "debug", debug, "enable debug log")
+	root.PersistentFlags().StringVar(&configFile, "config", configFile, "yaml config file, flags explicitly set take precedence")
+	root.PersistentFlags().StringVar(&configReload, "config-reload", configReload, "interval to check config file and reload sinks, routes and filters, 0 to disable")
 	root.PersistentFlags().BoolVar(&disableRevision, "disable-revision", disableRevision, "disable revision")
 	root.PersistentFlags().BoolVar(&outofCluster, "outof-cluster", outofCluster, "use outof cluster config directly")
 	root.PersistentFlags().StringVar(&ignoreBefore, "ignore-before", ignoreBefore, "ignore create before when start")
 	root.PersistentFlags().StringSliceVar(&excludes, "excludes", excludes, "excludes resource field when diff")
 	root.PersistentFlags().StringSliceVar(&includes, "includes", includes, "only include resource field when diff")
-	root.PersistentFlags().StringSliceVar(&includeResources, "resources", includeResources, "watch only these resource, default all, support Deployment, StatefulSet, DaemonSet")
+	root.PersistentFlags().StringSliceVar(&redacts, "redacts", redacts, "replace value of matched field with fingerprint when diff")
+	root.PersistentFlags().StringVar(&redactFlags, "redact-flags", redactFlags, "replace value of matched command line flag(--name=value) with fingerprint when diff, empty to disable, container log is always redacted")
+	root.PersistentFlags().StringSliceVar(&includeResources, "resources", includeResources, "watch only these resource, default Deployment, StatefulSet and DaemonSet, support Deployment, StatefulSet, DaemonSet, CronJob, Job, ConfigMap, Secret, HorizontalPodAutoscaler, Pod, Node")
+	root.PersistentFlags().StringSliceVar(&customResources, "custom-resources", customResources, "watch these custom resources, as resource.version.group, e.g. rollouts.v1alpha1.argoproj.io, see config to track readiness")
 	root.PersistentFlags().StringSliceVar(&includeNamespaces, "namespaces", includeNamespaces, "watch resource under these namepsace, default all")
 	root.PersistentFlags().StringVar(&resync, "resync", resync, "duration to resync resource")
+	root.PersistentFlags().BoolVar(&ignoreScaling, "ignore-scaling", ignoreScaling, "do not notify replicas change of workload, route action Scaled to suppress it per sink")
+	root.PersistentFlags().Int32Var(&restartThreshold, "restart-threshold", restartThreshold, "notify PodRestarting once restarts of container reach, 0 to disable")
+	root.PersistentFlags().Int64Var(&logLines, "log-lines", logLines, "attach the last lines of failing container log to failure notification, 0 to disable, require get pods/log")
+	root.PersistentFlags().Int64Var(&logBytes, "log-bytes", logBytes, "attach at most the last bytes of container log, see --log-lines")
+	root.PersistentFlags().StringVar(&logTimeout, "log-timeout", logTimeout, "timeout to fetch container log, see --log-lines")
+	root.PersistentFlags().StringVar(&rolloutDeadline, "rollout-deadline", rolloutDeadline, "mark rollout of DaemonSet and StatefulSet failed if not complete after")
+	root.PersistentFlags().StringVar(&imageLink, "image-link", imageLink, "go template to render link of image change, e.g. https://github.com/example/{{ .Repository }}/compare/{{ .FromTag }}...{{ .ToTag }}")
 	root.PersistentFlags().StringSliceVar(&webhooks, "webhooks", webhooks, "webhook to notify")
+	root.PersistentFlags().StringSliceVar(&slackWebhooks, "slack-webhooks", slackWebhooks, "slack incoming webhook to notify")
+	root.PersistentFlags().StringSliceVar(&larkWebhooks, "lark-webhooks", larkWebhooks, "lark/feishu bot webhook to notify, append secret as fragment(#secret) to enable sign")
+	root.PersistentFlags().StringSliceVar(&dingtalkWebhooks, "dingtalk-webhooks", dingtalkWebhooks, "dingtalk robot webhook to notify, append secret as fragment(#secret) to enable sign")
+	root.PersistentFlags().StringSliceVar(&wecomWebhooks, "wecom-webhooks", wecomWebhooks, "wecom robot webhook to notify")
+	root.PersistentFlags().StringVar(&tmpl, "template", tmpl, "go template to render message, which replace the markdown of slack, lark, dingtalk and wecom, define template named as action(Created, Updated, Deleted, NotReady) to override")
+	root.PersistentFlags().StringVar(&tmplFile, "template-file", tmplFile, "file contains go template to render message, see --template")
+	root.PersistentFlags().StringVar(&diffStyle, "diff-style", diffStyle, "render changes as inline or unified diff of yaml")
+	root.PersistentFlags().StringSliceVar(&kafkaBrokers, "kafka-brokers", kafkaBrokers, "kafka brokers to notify")
+	root.PersistentFlags().StringVar(&kafkaTopic, "kafka-topic", kafkaTopic, "kafka topic to notify")
+	root.PersistentFlags().StringVar(&kafkaSASLUser, "kafka-sasl-user", kafkaSASLUser, "kafka SASL/PLAIN user")
+	root.PersistentFlags().StringVar(&kafkaSASLPassword, "kafka-sasl-password", kafkaSASLPassword, "kafka SASL/PLAIN password")
+	root.PersistentFlags().BoolVar(&kafkaTLS, "kafka-tls", kafkaTLS, "enable tls when connect kafka")
+	root.PersistentFlags().BoolVar(&kafkaTLSSkipVerify, "kafka-tls-skip-verify", kafkaTLSSkipVerify, "skip verify kafka server certificate")
 
 	root.PersistentPreRunE = func(*cobra.Command, []string) error {
 		if debug {
@@ -75,69 +134,48 @@ func main() {
 	}
 
 	root.RunE = func(cmd *cobra.Command, args []string) error {
-		var err error
-		opts := []sentry.Option{}
-
-		if debug {
-			opts = append(opts, sentry.EnableDebug())
-		}
-		if disableRevision {
-			opts = append(opts, sentry.DisableRevision())
-		}
-
-		if len(excludes) > 0 {
-			rExcludes := make([]*regexp.Regexp, 0, len(excludes))
-			for _, exclude := range excludes {
-				reg, err := regexp.Compile(exclude)
-				if err != nil {
-					return fmt.Errorf("compile regex %s: %w", exclude, err)
-				}
-				rExcludes = append(rExcludes, reg)
-			}
-			opts = append(opts, sentry.WithExcludes(rExcludes))
-		}
-
-		if len(includes) > 0 {
-			rIncludes := make([]*regexp.Regexp, 0, len(includes))
-			for _, include := range includes {
-				reg, err := regexp.Compile(include)
-				if err != nil {
-					return fmt.Errorf("compile regex %s: %w", include, err)
-				}
-				rIncludes = append(rIncludes, reg)
+		var fileConfig *config.Config
+		if configFile != "" {
+			var err error
+			fileConfig, err = config.Load(configFile)
+			if err != nil {
+				return err
 			}
-			opts = append(opts, sentry.WithIncludes(rIncludes))
 		}
 
-		if len(includeResources) > 0 {
-			opts = append(opts, sentry.IncludeResources(includeResources...))
-		}
-		if len(includeNamespaces) > 0 {
-			opts = append(opts, sentry.IncludeNamespaces(includeNamespaces...))
+		s := newSettings(cmd, fileConfig)
+		opts, err := s.options()
+		if err != nil {
+			return err
 		}
 
-		d, err := time.ParseDuration(ignoreBefore)
+		notifier, closer, err := buildNotifier(fileConfig)
 		if err != nil {
-			return fmt.Errorf("parse duration %s: %w", ignoreBefore, err)
+			return err
 		}
-		opts = append(opts, sentry.WithIgnoreCreatedBefore(d))
+		// NOTE: worker may hold notifier of previous config when reload
+		swappable := notify.NewSwappable(notifier)
+		closerMu := sync.Mutex{}
+		defer func() {
+			closerMu.Lock()
+			defer closerMu.Unlock()
+			_ = closer()
+		}()
 
 		ctx, cancel := context.WithCancel(cmd.Context())
 		defer cancel()
 
-		notifyFunc := notify.StdoutNotify()
-		if len(webhooks) > 0 {
-			notifyFunc = notify.WebhooksNotify(webhooks)
-		}
-
-		var informer informers.SharedInformerFactory
-		{
-			d, err := time.ParseDuration(resync)
-			if err != nil {
-				return fmt.Errorf("prase duration %s: %w", resync, err)
-			}
-			informer = informers.NewSharedInformerFactory(kubeClient, d)
+		resyncPeriod, err := time.ParseDuration(s.resync)
+		if err != nil {
+			return fmt.Errorf("prase duration %s: %w", s.resync, err)
 		}
+		informer := informers.NewSharedInformerFactory(kubeClient, resyncPeriod)
+		// NOTE: watch only warning events, which enrich failure notifications
+		eventInformer := informers.NewSharedInformerFactoryWithOptions(
+			kubeClient, resyncPeriod,
+			informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+				opts.FieldSelector = fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String()
+			}))
 
 		ctl, err := sentry.New(
 			informer.Core().V1().Pods(),
@@ -145,16 +183,80 @@ func main() {
 			informer.Apps().V1().Deployments(),
 			informer.Apps().V1().StatefulSets(),
 			informer.Apps().V1().DaemonSets(),
+			informer.Batch().V1().CronJobs(),
+			informer.Batch().V1().Jobs(),
+			informer.Core().V1().ConfigMaps(),
+			informer.Core().V1().Secrets(),
+			informer.Autoscaling().V2beta2().HorizontalPodAutoscalers(),
+			informer.Core().V1().Nodes(),
+			eventInformer.Core().V1().Events(),
 			informer.Apps().V1().ControllerRevisions(),
-			notifyFunc,
+			swappable,
 			opts...,
 		)
 		if err != nil {
 			return err
 		}
 
+		if len(s.customs) > 0 {
+			dynamicClient, err := client.NewDynamicClient(outofCluster)
+			if err != nil {
+				return err
+			}
+			dynamicInformer := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, resyncPeriod)
+			for _, c := range s.customs {
+				cr, err := sentry.NewCustomResource(c.Resource, c.Ready, c.ReadyValue)
+				if err != nil {
+					return err
+				}
+				ctl.AddCustomResource(cr, dynamicInformer.ForResource(cr.GVR))
+			}
+			go dynamicInformer.Start(ctx.Done())
+		}
+
+		if configFile != "" {
+			d, err := time.ParseDuration(configReload)
+			if err != nil {
+				return fmt.Errorf("parse duration %s: %w", configReload, err)
+			}
+			if d > 0 {
+				go config.Watch(ctx, configFile, d, func(c *config.Config) {
+					ns := newSettings(cmd, c)
+					if !reflect.DeepEqual(ns.resources, s.resources) ||
+						!reflect.DeepEqual(ns.customs, s.customs) ||
+						ns.resync != s.resync {
+						log.Warn().Msgf("resources, customResources and resync require restart, ignore them when reload")
+					}
+
+					opts, err := ns.options()
+					if err != nil {
+						log.Warn().Err(err).Msgf("ignore config %s", configFile)
+						return
+					}
+					notifier, newCloser, err := buildNotifier(c)
+					if err != nil {
+						log.Warn().Err(err).Msgf("ignore config %s", configFile)
+						return
+					}
+
+					// NOTE: close previous sinks after in-flight events are sent
+					swappable.Swap(notifier)
+					ctl.Reload(swappable, opts...)
+
+					closerMu.Lock()
+					defer closerMu.Unlock()
+					if err := closer(); err != nil {
+						log.Warn().Err(err).Msgf("close previous sinks")
+					}
+					closer = newCloser
+					log.Info().Msgf("reload config %s", configFile)
+				})
+			}
+		}
+
 		go ctl.Run(1, ctx.Done())
 		go informer.Start(ctx.Done())
+		go eventInformer.Start(ctx.Done())
 
 		sigterm := make(chan os.Signal, 1)
 		signal.Notify(sigterm, syscall.SIGTERM)
@@ -168,3 +270,219 @@ func main() {
 		log.Err(err).Send()
 	}
 }
+
+// settings is the effective options, config override flags except explicitly set.
+type settings struct {
+	excludes     []string
+	includes     []string
+	redacts      []string
+	redactFlags  string
+	resources    []string
+	customs      []config.CustomResource
+	namespaces   []string
+	ignoreBefore string
+	resync       string
+}
+
+func newSettings(cmd *cobra.Command, c *config.Config) settings {
+	s := settings{
+		excludes:     excludes,
+		includes:     includes,
+		redacts:      redacts,
+		redactFlags:  redactFlags,
+		resources:    includeResources,
+		namespaces:   includeNamespaces,
+		ignoreBefore: ignoreBefore,
+		resync:       resync,
+	}
+	for _, resource := range customResources {
+		s.customs = append(s.customs, config.CustomResource{Resource: resource})
+	}
+	if c == nil {
+		return s
+	}
+
+	flags := cmd.Flags()
+	if len(c.Excludes) > 0 && !flags.Changed("excludes") {
+		s.excludes = c.Excludes
+	}
+	if len(c.Includes) > 0 && !flags.Changed("includes") {
+		s.includes = c.Includes
+	}
+	if len(c.Redacts) > 0 && !flags.Changed("redacts") {
+		s.redacts = c.Redacts
+	}
+	if c.RedactFlags != "" && !flags.Changed("redact-flags") {
+		s.redactFlags = c.RedactFlags
+	}
+	if len(c.Resources) > 0 && !flags.Changed("resources") {
+		s.resources = c.Resources
+	}
+	if len(c.CustomResources) > 0 && !flags.Changed("custom-resources") {
+		s.customs = c.CustomResources
+	}
+	if len(c.Namespaces) > 0 && !flags.Changed("namespaces") {
+		s.namespaces = c.Namespaces
+	}
+	if c.IgnoreBefore != "" && !flags.Changed("ignore-before") {
+		s.ignoreBefore = c.IgnoreBefore
+	}
+	if c.Resync != "" && !flags.Changed("resync") {
+		s.resync = c.Resync
+	}
+	return s
+}
+
+func (s settings) options() ([]sentry.Option, error) {
+	opts := []sentry.Option{}
+
+	if debug {
+		opts = append(opts, sentry.EnableDebug())
+	}
+	if disableRevision {
+		opts = append(opts, sentry.DisableRevision())
+	}
+	if ignoreScaling {
+		opts = append(opts, sentry.IgnoreScaling())
+	}
+
+	if len(s.excludes) > 0 {
+		rExcludes := make([]*regexp.Regexp, 0, len(s.excludes))
+		for _, exclude := range s.excludes {
+			reg, err := regexp.Compile(exclude)
+			if err != nil {
+				return nil, fmt.Errorf("compile regex %s: %w", exclude, err)
+			}
+			rExcludes = append(rExcludes, reg)
+		}
+		opts = append(opts, sentry.WithExcludes(rExcludes))
+	}
+
+	if len(s.includes) > 0 {
+		rIncludes := make([]*regexp.Regexp, 0, len(s.includes))
+		for _, include := range s.includes {
+			reg, err := regexp.Compile(include)
+			if err != nil {
+				return nil, fmt.Errorf("compile regex %s: %w", include, err)
+			}
+			rIncludes = append(rIncludes, reg)
+		}
+		opts = append(opts, sentry.WithIncludes(rIncludes))
+	}
+
+	redactor, err := diff.NewRedactor(s.redacts, s.redactFlags)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, sentry.WithRedactor(redactor))
+
+	if len(s.resources) > 0 {
+		opts = append(opts, sentry.IncludeResources(s.resources...))
+	}
+	if len(s.namespaces) > 0 {
+		opts = append(opts, sentry.IncludeNamespaces(s.namespaces...))
+	}
+
+	d, err := time.ParseDuration(s.ignoreBefore)
+	if err != nil {
+		return nil, fmt.Errorf("parse duration %s: %w", s.ignoreBefore, err)
+	}
+	opts = append(opts, sentry.WithIgnoreCreatedBefore(d))
+
+	if imageLink != "" {
+		link, err := template.New("image-link").Parse(imageLink)
+		if err != nil {
+			return nil, fmt.Errorf("parse image link %s: %w", imageLink, err)
+		}
+		opts = append(opts, sentry.WithImageLink(link))
+	}
+
+	if d, err = time.ParseDuration(rolloutDeadline); err != nil {
+		return nil, fmt.Errorf("parse duration %s: %w", rolloutDeadline, err)
+	}
+	opts = append(opts, sentry.WithRolloutDeadline(d))
+	opts = append(opts, sentry.WithRestartThreshold(restartThreshold))
+
+	if logLines > 0 {
+		if d, err = time.ParseDuration(logTimeout); err != nil {
+			return nil, fmt.Errorf("parse duration %s: %w", logTimeout, err)
+		}
+		opts = append(opts, sentry.WithLogTail(&sentry.LogTail{
+			Pods:    kubeClient.CoreV1(),
+			Lines:   logLines,
+			Bytes:   logBytes,
+			Timeout: d,
+		}))
+	}
+
+	return opts, nil
+}
+
+// buildNotifier combine sinks from flags and config file,
+// the returned close func release all sinks.
+func buildNotifier(fileConfig *config.Config) (notify.Notifier, func() error, error) {
+	flagConfig := config.Config{Template: tmpl, TemplateFile: tmplFile}
+	if len(webhooks) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "webhooks", Type: config.SinkWebhook, URLs: webhooks})
+	}
+	if len(slackWebhooks) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "slack", Type: config.SinkSlack, URLs: slackWebhooks})
+	}
+	if len(larkWebhooks) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "lark", Type: config.SinkLark, URLs: larkWebhooks})
+	}
+	if len(dingtalkWebhooks) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "dingtalk", Type: config.SinkDingtalk, URLs: dingtalkWebhooks})
+	}
+	if len(wecomWebhooks) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "wecom", Type: config.SinkWecom, URLs: wecomWebhooks})
+	}
+	if len(kafkaBrokers) > 0 {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{
+			Name:          "kafka",
+			Type:          config.SinkKafka,
+			Brokers:       kafkaBrokers,
+			Topic:         kafkaTopic,
+			SASLUser:      kafkaSASLUser,
+			SASLPassword:  kafkaSASLPassword,
+			TLS:           kafkaTLS,
+			TLSSkipVerify: kafkaTLSSkipVerify,
+		})
+	}
+
+	if len(flagConfig.Sinks) == 0 && (fileConfig == nil || len(fileConfig.Sinks) == 0) {
+		flagConfig.Sinks = append(flagConfig.Sinks, config.Sink{Name: "stdout", Type: config.SinkStdout})
+	}
+	for i := range flagConfig.Sinks {
+		flagConfig.Sinks[i].Diff = diffStyle
+	}
+
+	notifiers := []notify.Notifier{}
+	closers := []func() error{}
+	closeAll := func() error {
+		var err error
+		for _, closer := range closers {
+			if cerr := closer(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+	for _, c := range []*config.Config{&flagConfig, fileConfig} {
+		if c == nil || len(c.Sinks) == 0 {
+			continue
+		}
+		n, closer, err := c.Notifier(notify.DefaultTimeFormat, debug)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, err
+		}
+		notifiers = append(notifiers, n)
+		closers = append(closers, closer)
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], closeAll, nil
+	}
+	return notify.Multi(notifiers...), closeAll, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -56,3 +57,21 @@ func NewKubeClient(outofCluster bool) (kubernetes.Interface, error) {
 
 	return newKubeInCluster()
 }
+
+// NewDynamicClient is used to watch custom resources.
+func NewDynamicClient(outofCluster bool) (dynamic.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if outofCluster || err != nil {
+		config, err = buildOutofClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not get kubernetes config: %w", err)
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("can not create dynamic client: %w", err)
+	}
+
+	return client, nil
+}
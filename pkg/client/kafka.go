@@ -1,7 +1,38 @@
 package client
 
-import "github.com/Shopify/sarama"
+import (
+	"crypto/tls"
 
-func NewKafkaProducer(addrs []string) (sarama.AsyncProducer, error) {
-	return sarama.NewAsyncProducer(addrs, nil)
+	"github.com/Shopify/sarama"
+)
+
+type KafkaOptions struct {
+	// SASLUser and SASLPassword, enable SASL/PLAIN if SASLUser is not empty
+	SASLUser     string
+	SASLPassword string
+
+	TLS           bool
+	TLSSkipVerify bool
+}
+
+func NewKafkaProducer(addrs []string, opts KafkaOptions) (sarama.AsyncProducer, error) {
+	config := sarama.NewConfig()
+	config.Producer.Return.Errors = true
+	config.Producer.Return.Successes = false
+
+	if opts.SASLUser != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		config.Net.SASL.User = opts.SASLUser
+		config.Net.SASL.Password = opts.SASLPassword
+	}
+
+	if opts.TLS {
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = &tls.Config{
+			InsecureSkipVerify: opts.TLSSkipVerify, // nolint:gosec
+		}
+	}
+
+	return sarama.NewAsyncProducer(addrs, config)
 }
@@ -0,0 +1,276 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the declarative configuration of kubenotify,
+// e.g.
+//
+//	namespaces: [payments, infra]
+//	sinks:
+//	- name: payments
+//	  type: slack
+//	  urls: [https://hooks.slack.com/services/xxx]
+//	- name: infra
+//	  type: lark
+//	  urls: [https://open.feishu.cn/open-apis/bot/v2/hook/xxx#secret]
+//	routes:
+//	- match: {kinds: [Deployment], name: "payments-.*"}
+//	  sinks: [payments]
+//	- match: {namespaces: [infra], kinds: [DaemonSet]}
+//	  sinks: [infra]
+type Config struct {
+	Excludes []string `json:"excludes,omitempty"`
+	Includes []string `json:"includes,omitempty"`
+	// Redacts and RedactFlags replace sensitive value with fingerprint, see diff.Redactor
+	Redacts      []string `json:"redacts,omitempty"`
+	RedactFlags  string   `json:"redactFlags,omitempty"`
+	Resources    []string `json:"resources,omitempty"`
+	Namespaces   []string `json:"namespaces,omitempty"`
+	IgnoreBefore string   `json:"ignoreBefore,omitempty"`
+	Resync       string   `json:"resync,omitempty"`
+	// CustomResources is watched by dynamic informer, e.g. CRD, require restart
+	CustomResources []CustomResource `json:"customResources,omitempty"`
+
+	// Template and TemplateFile is used by sink without template
+	Template     string `json:"template,omitempty"`
+	TemplateFile string `json:"templateFile,omitempty"`
+
+	Sinks []Sink `json:"sinks,omitempty"`
+	// Routes is matched in order, event is sent to all sinks if no route.
+	Routes []Route `json:"routes,omitempty"`
+	// DefaultSinks receive event which not matched by any route.
+	DefaultSinks []string `json:"defaultSinks,omitempty"`
+}
+
+// CustomResource, e.g.
+//
+//	resource: rollouts.v1alpha1.argoproj.io
+//	ready: "{.status.phase}"
+//	readyValue: Healthy
+type CustomResource struct {
+	// Resource, resource.version.group
+	Resource string `json:"resource"`
+	// Ready, jsonpath of readiness, track rollout if set, e.g. {.status.conditions[?(@.type=="Ready")].status}
+	Ready string `json:"ready,omitempty"`
+	// ReadyValue, default True
+	ReadyValue string `json:"readyValue,omitempty"`
+}
+
+const (
+	SinkStdout   = "stdout"
+	SinkWebhook  = "webhook"
+	SinkSlack    = "slack"
+	SinkLark     = "lark"
+	SinkDingtalk = "dingtalk"
+	SinkWecom    = "wecom"
+	SinkKafka    = "kafka"
+)
+
+type Sink struct {
+	Name string `json:"name"`
+	// Type, support stdout, webhook, slack, lark, dingtalk, wecom and kafka
+	Type string `json:"type"`
+
+	// URLs, for webhook, slack, lark, dingtalk and wecom,
+	// append secret as fragment(#secret) to enable sign of lark and dingtalk
+	URLs []string `json:"urls,omitempty"`
+
+	Template     string `json:"template,omitempty"`
+	TemplateFile string `json:"templateFile,omitempty"`
+	// Diff, render changes as inline(default) or unified diff of yaml,
+	// for stdout, webhook, slack, lark, dingtalk and wecom
+	Diff string `json:"diff,omitempty"`
+
+	// Brokers, Topic, SASL and TLS, for kafka
+	Brokers       []string `json:"brokers,omitempty"`
+	Topic         string   `json:"topic,omitempty"`
+	SASLUser      string   `json:"saslUser,omitempty"`
+	SASLPassword  string   `json:"saslPassword,omitempty"`
+	TLS           bool     `json:"tls,omitempty"`
+	TLSSkipVerify bool     `json:"tlsSkipVerify,omitempty"`
+}
+
+type Route struct {
+	Match Match    `json:"match"`
+	Sinks []string `json:"sinks"`
+	// Continue to match following routes after matched
+	Continue bool `json:"continue,omitempty"`
+}
+
+// Match select event, empty field match all.
+type Match struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	Kinds      []string `json:"kinds,omitempty"`
+	// Name is a regex which must match the whole name
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Actions, e.g. Created, Updated, Deleted, RolloutFailed and JobFailed, see notify.Action
+	Actions []string `json:"actions,omitempty"`
+}
+
+func Load(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+	return Parse(b)
+}
+
+func Parse(b []byte) (*Config, error) {
+	c := Config{}
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return &c, nil
+}
+
+// Notifier build notifier from sinks and routes,
+// the returned close func release resources, e.g. kafka producer.
+func (c *Config) Notifier(timeFormat string, verbose bool) (notify.Notifier, func() error, error) {
+	closers := []func() error{}
+	closeAll := func() error {
+		var err error
+		for _, closer := range closers {
+			if cerr := closer(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}
+
+	tmpl, err := readTemplate(c.Template, c.TemplateFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sinks := map[string]notify.Notifier{}
+	all := []notify.Notifier{}
+	for _, sink := range c.Sinks {
+		if _, ok := sinks[sink.Name]; ok {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("duplicate sink %s", sink.Name)
+		}
+		n, closer, err := sink.notifier(tmpl, timeFormat, verbose)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("build sink %s: %w", sink.Name, err)
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		sinks[sink.Name] = n
+		all = append(all, n)
+	}
+
+	lookup := func(names []string) ([]notify.Notifier, error) {
+		notifiers := make([]notify.Notifier, 0, len(names))
+		for _, name := range names {
+			n, ok := sinks[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown sink %s", name)
+			}
+			notifiers = append(notifiers, n)
+		}
+		return notifiers, nil
+	}
+
+	if len(c.Routes) == 0 {
+		return notify.Multi(all...), closeAll, nil
+	}
+
+	routes := make([]notify.Route, 0, len(c.Routes))
+	for i, route := range c.Routes {
+		match, err := route.Match.build()
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("build match of route[%d]: %w", i, err)
+		}
+		notifiers, err := lookup(route.Sinks)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("build route[%d]: %w", i, err)
+		}
+		routes = append(routes, notify.Route{
+			Match:    match,
+			Notifier: notify.Multi(notifiers...),
+			Continue: route.Continue,
+		})
+	}
+
+	var fallback notify.Notifier
+	if len(c.DefaultSinks) > 0 {
+		notifiers, err := lookup(c.DefaultSinks)
+		if err != nil {
+			_ = closeAll()
+			return nil, nil, fmt.Errorf("build default sinks: %w", err)
+		}
+		fallback = notify.Multi(notifiers...)
+	}
+
+	return notify.Router(routes, fallback), closeAll, nil
+}
+
+func (m Match) build() (notify.Match, error) {
+	match := notify.Match{Labels: m.Labels}
+	if len(m.Namespaces) > 0 {
+		match.Namespaces = map[string]bool{}
+		for _, ns := range m.Namespaces {
+			match.Namespaces[ns] = true
+		}
+	}
+	if len(m.Kinds) > 0 {
+		match.Kinds = map[string]bool{}
+		for _, kind := range m.Kinds {
+			match.Kinds[kind] = true
+		}
+	}
+	if len(m.Actions) > 0 {
+		match.Actions = map[notify.Action]bool{}
+		for _, action := range m.Actions {
+			if !notify.Actions[notify.Action(action)] {
+				return match, fmt.Errorf("unknown action %s", action)
+			}
+			match.Actions[notify.Action(action)] = true
+		}
+	}
+	if m.Name != "" {
+		reg, err := regexp.Compile("^(?:" + m.Name + ")$")
+		if err != nil {
+			return match, fmt.Errorf("compile regex %s: %w", m.Name, err)
+		}
+		match.Name = reg
+	}
+	return match, nil
+}
+
+// templateFiles return template files referenced by config and sinks.
+func (c *Config) templateFiles() []string {
+	files := []string{}
+	if c.TemplateFile != "" {
+		files = append(files, c.TemplateFile)
+	}
+	for _, sink := range c.Sinks {
+		if sink.TemplateFile != "" {
+			files = append(files, sink.TemplateFile)
+		}
+	}
+	return files
+}
+
+func readTemplate(tmpl, file string) (string, error) {
+	if file == "" {
+		return tmpl, nil
+	}
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read template %s: %w", file, err)
+	}
+	return string(b), nil
+}
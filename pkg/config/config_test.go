@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`
+namespaces: [payments, infra]
+sinks:
+- name: payments
+  type: webhook
+  urls: [http://127.0.0.1/payments]
+- name: infra
+  type: stdout
+routes:
+- match: {kinds: [Deployment], name: "payments-.*"}
+  sinks: [payments]
+- match: {namespaces: [infra], kinds: [DaemonSet], actions: [Updated]}
+  sinks: [infra]
+`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"payments", "infra"}, c.Namespaces)
+	require.Len(t, c.Sinks, 2)
+	require.Equal(t, []string{"DaemonSet"}, c.Routes[1].Match.Kinds)
+
+	_, closer, err := c.Notifier(notify.DefaultTimeFormat, false)
+	require.NoError(t, err)
+	require.NoError(t, closer())
+
+	_, err = Parse([]byte(`sink: []`))
+	require.Error(t, err)
+}
+
+func TestNotifierInvalid(t *testing.T) {
+	fixtures := []struct {
+		name string
+		c    Config
+	}{
+		{
+			"unknown type",
+			Config{Sinks: []Sink{{Name: "a", Type: "unknown"}}},
+		},
+		{
+			"duplicate sink",
+			Config{Sinks: []Sink{{Name: "a", Type: SinkStdout}, {Name: "a", Type: SinkStdout}}},
+		},
+		{
+			"unknown sink",
+			Config{
+				Sinks:  []Sink{{Name: "a", Type: SinkStdout}},
+				Routes: []Route{{Sinks: []string{"b"}}},
+			},
+		},
+		{
+			"invalid name",
+			Config{
+				Sinks:  []Sink{{Name: "a", Type: SinkStdout}},
+				Routes: []Route{{Match: Match{Name: "("}, Sinks: []string{"a"}}},
+			},
+		},
+		{
+			"unknown action",
+			Config{
+				Sinks:  []Sink{{Name: "a", Type: SinkStdout}},
+				Routes: []Route{{Match: Match{Actions: []string{"RolloutFail"}}, Sinks: []string{"a"}}},
+			},
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			_, _, err := fixture.c.Notifier(notify.DefaultTimeFormat, false)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMatchName(t *testing.T) {
+	m, err := Match{Name: "payments-.*"}.build()
+	require.NoError(t, err)
+	require.True(t, m.Match(notify.Event{Name: "payments-api"}))
+	require.False(t, m.Match(notify.Event{Name: "old-payments-api"}))
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/j2gg0s/kubenotify/pkg/client"
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+)
+
+// notifier build notifier of sink, defaultTmpl is used if sink has no template.
+func (s Sink) notifier(defaultTmpl string, timeFormat string, verbose bool) (notify.Notifier, func() error, error) {
+	tmpl, err := readTemplate(s.Template, s.TemplateFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tmpl == "" {
+		tmpl = defaultTmpl
+	}
+
+	style, err := notify.ParseDiffStyle(s.Diff)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sink %s: %w", s.Name, err)
+	}
+
+	format := notify.TextFormatter(timeFormat, verbose)
+	if style == notify.DiffUnified {
+		format = notify.UnifiedFormatter(timeFormat, verbose)
+	}
+	// body replace the markdown of chat sinks, empty if action has no template
+	var body notify.Formatter
+	if tmpl != "" {
+		format, err = notify.TemplateFormatter(tmpl, format)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, _ = notify.TemplateFormatter(tmpl, func(notify.Event) string { return "" })
+	}
+
+	switch s.Type {
+	case SinkStdout:
+		return notify.StdoutNotify(format), nil, nil
+	case SinkWebhook:
+		return notify.WebhooksNotify(s.URLs, format), nil, nil
+	case SinkSlack:
+		return notify.SlacksNotify(s.URLs, timeFormat, style, format, body), nil, nil
+	case SinkLark:
+		n, err := notify.LarksNotify(s.URLs, timeFormat, style, body)
+		return n, nil, err
+	case SinkDingtalk:
+		n, err := notify.DingtalksNotify(s.URLs, timeFormat, style, body)
+		return n, nil, err
+	case SinkWecom:
+		return notify.WecomsNotify(s.URLs, timeFormat, style, body), nil, nil
+	case SinkKafka:
+		topic := s.Topic
+		if topic == "" {
+			topic = "kubenotify"
+		}
+		producer, err := client.NewKafkaProducer(s.Brokers, client.KafkaOptions{
+			SASLUser:      s.SASLUser,
+			SASLPassword:  s.SASLPassword,
+			TLS:           s.TLS,
+			TLSSkipVerify: s.TLSSkipVerify,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("create kafka producer: %w", err)
+		}
+		n := notify.KafkaNotify(producer, topic, nil)
+		return n, n.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unknown sink type: %s", s.Type)
+}
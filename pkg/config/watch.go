@@ -0,0 +1,62 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Watch poll path and template files referenced by it every interval and call onChange if content changed,
+// invalid config is logged and ignored.
+// Polling instead of inotify, since ConfigMap is mounted by symlink swap.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func(*Config)) {
+	last, _, err := snapshot(path)
+	if err != nil {
+		log.Warn().Err(err).Msgf("read config %s", path)
+	}
+
+	wait.UntilWithContext(ctx, func(context.Context) {
+		b, c, err := snapshot(path)
+		if b == nil {
+			log.Warn().Err(err).Msgf("read config %s", path)
+			return
+		}
+		if bytes.Equal(b, last) {
+			return
+		}
+		last = b
+
+		if err != nil {
+			log.Warn().Err(err).Msgf("ignore invalid config %s", path)
+			return
+		}
+		log.Info().Msgf("config %s changed", path)
+		onChange(c)
+	}, interval)
+}
+
+// snapshot read config and template files referenced by it,
+// content is returned with error if config is invalid, nil if unreadable.
+func snapshot(path string) ([]byte, *Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := Parse(b)
+	if err != nil {
+		return b, nil, err
+	}
+	for _, file := range c.templateFiles() {
+		t, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read template %s: %w", file, err)
+		}
+		b = append(append(b, 0), t...)
+	}
+	return b, c, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubenotify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	// NOTE: write by rename, like symlink swap of ConfigMap, avoid reading partial file
+	write := func(content string) {
+		tmp := path + ".tmp"
+		require.NoError(t, ioutil.WriteFile(tmp, []byte(content), 0600))
+		require.NoError(t, os.Rename(tmp, path))
+	}
+	write(`namespaces: [a]`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan *Config, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(c *Config) {
+		changed <- c
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	// invalid config is ignored
+	write(`unknown: [b]`)
+	time.Sleep(50 * time.Millisecond)
+	write(`namespaces: [b]`)
+
+	select {
+	case c := <-changed:
+		require.Equal(t, []string{"b"}, c.Namespaces)
+	case <-time.After(time.Second):
+		t.Fatal("config not reloaded")
+	}
+}
+
+func TestWatchTemplateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubenotify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	tmpl := filepath.Join(dir, "template.tmpl")
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte(`{{ .Name }}`), 0600))
+	require.NoError(t, ioutil.WriteFile(path, []byte("templateFile: "+tmpl), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan *Config, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(c *Config) {
+		changed <- c
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, ioutil.WriteFile(tmpl, []byte(`{{ .Kind }}`), 0600))
+
+	select {
+	case c := <-changed:
+		require.Equal(t, tmpl, c.TemplateFile)
+	case <-time.After(time.Second):
+		t.Fatal("template not reloaded")
+	}
+}
@@ -0,0 +1,254 @@
+// Package diff compare kubernetes object as generic map,
+// element of list is matched by its merge key, e.g. name of container,
+// so that the path is readable, e.g. spec.template.spec.containers[app].image.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Change struct {
+	Path string
+	From interface{}
+	To   interface{}
+	// Delta is the human readable delta, e.g. memory limit +512Mi
+	Delta string
+}
+
+// MergeKeys is the key to match element of list, keyed by field name of the list.
+// List is compared by index if its field is not listed or any element has no unique key.
+var MergeKeys = map[string][]string{
+	"containers":          {"name"},
+	"initContainers":      {"name"},
+	"ephemeralContainers": {"name"},
+	"env":                 {"name"},
+	"volumes":             {"name"},
+	"volumeMounts":        {"mountPath"},
+	"volumeDevices":       {"devicePath"},
+	"ports":               {"containerPort", "port"},
+	"tolerations":         {"key"},
+	"imagePullSecrets":    {"name"},
+	"hostAliases":         {"ip"},
+}
+
+// Diff compare before and after, which are converted to map by json.
+func Diff(before, after interface{}) ([]Change, error) {
+	bm, am, err := toMaps(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{}
+	d.diffMap("", bm, am)
+	return d.changes, nil
+}
+
+// Hunks group changes by their nearest parent map or list, e.g. args of container,
+// so that change is rendered with its siblings as context.
+// Change is kept as is if its parent is the object or a top-level field, e.g. spec,
+// or any change of the parent is excluded, e.g. by excludes or rendered as image summary.
+// Delta of changes in the same parent is joined.
+func Hunks(before, after interface{}, changes []Change, excluded []Change) ([]Change, error) {
+	bm, am, err := toMaps(before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	d := differ{parents: map[string]Change{}}
+	d.diffMap("", bm, am)
+
+	skip := map[string]bool{}
+	for _, change := range excluded {
+		if parent, ok := d.parents[change.Path]; ok {
+			skip[parent.Path] = true
+		}
+	}
+
+	hunks := []Change{}
+	index := map[string]int{}
+	for _, change := range changes {
+		parent, ok := d.parents[change.Path]
+		if !ok || skip[parent.Path] || !strings.ContainsAny(parent.Path, ".[") {
+			hunks = append(hunks, change)
+			continue
+		}
+		i, ok := index[parent.Path]
+		if !ok {
+			i = len(hunks)
+			index[parent.Path] = i
+			hunks = append(hunks, parent)
+		}
+		if change.Delta != "" {
+			if hunks[i].Delta != "" {
+				hunks[i].Delta += ", "
+			}
+			hunks[i].Delta += change.Delta
+		}
+	}
+	return hunks, nil
+}
+
+func toMaps(before, after interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	bm, err := ToMap(before)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert %T to map: %w", before, err)
+	}
+	am, err := ToMap(after)
+	if err != nil {
+		return nil, nil, fmt.Errorf("convert %T to map: %w", after, err)
+	}
+	return bm, am, nil
+}
+
+func ToMap(obj interface{}) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if b, err := json.Marshal(obj); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// differ collect changes, and the parent map or list of each change if parents is not nil.
+type differ struct {
+	changes []Change
+	parents map[string]Change
+}
+
+func (d *differ) diffValue(parent Change, path string, field string, from, to interface{}) {
+	switch fv := from.(type) {
+	case map[string]interface{}:
+		if tv, ok := to.(map[string]interface{}); ok {
+			d.diffMap(path, fv, tv)
+			return
+		}
+	case []interface{}:
+		if tv, ok := to.([]interface{}); ok {
+			d.diffList(path, field, fv, tv)
+			return
+		}
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	equal, delta := normalize(path, from, to)
+	if equal {
+		return
+	}
+	d.changes = append(d.changes, Change{Path: path, From: from, To: to, Delta: delta})
+	if d.parents != nil {
+		d.parents[path] = parent
+	}
+}
+
+func (d *differ) diffMap(path string, from, to map[string]interface{}) {
+	keys := make([]string, 0, len(from)+len(to))
+	for k := range from {
+		keys = append(keys, k)
+	}
+	for k := range to {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	parent := Change{Path: path, From: from, To: to}
+	for _, k := range keys {
+		d.diffValue(parent, join(path, k), k, from[k], to[k])
+	}
+}
+
+func (d *differ) diffList(path string, field string, from, to []interface{}) {
+	parent := Change{Path: path, From: from, To: to}
+	fromKeys, fok := listKeys(field, from)
+	toKeys, tok := listKeys(field, to)
+	if !fok || !tok {
+		n := len(from)
+		if len(to) > n {
+			n = len(to)
+		}
+		for i := 0; i < n; i++ {
+			var fv, tv interface{}
+			if i < len(from) {
+				fv = from[i]
+			}
+			if i < len(to) {
+				tv = to[i]
+			}
+			d.diffValue(parent, join(path, strconv.Itoa(i)), "", fv, tv)
+		}
+		return
+	}
+
+	fromIndex := make(map[string]int, len(fromKeys))
+	for i, k := range fromKeys {
+		fromIndex[k] = i
+	}
+	toIndex := make(map[string]int, len(toKeys))
+	for i, k := range toKeys {
+		toIndex[k] = i
+	}
+
+	// NOTE: order by after, then removed
+	for i, k := range toKeys {
+		var fv interface{}
+		if j, ok := fromIndex[k]; ok {
+			fv = from[j]
+		}
+		d.diffValue(parent, fmt.Sprintf("%s[%s]", path, k), "", fv, to[i])
+	}
+	for i, k := range fromKeys {
+		if _, ok := toIndex[k]; !ok {
+			d.diffValue(parent, fmt.Sprintf("%s[%s]", path, k), "", from[i], nil)
+		}
+	}
+}
+
+// listKeys return merge key of each element, false if any element has no unique key.
+func listKeys(field string, list []interface{}) ([]string, bool) {
+	names, ok := MergeKeys[field]
+	if !ok {
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, elem := range list {
+		key := elemKey(names, elem)
+		if key == "" || seen[key] {
+			return nil, false
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, true
+}
+
+// elemKey return the first non-empty merge key of element, empty if none.
+func elemKey(names []string, elem interface{}) string {
+	m, ok := elem.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, name := range names {
+		if v, ok := m[name]; ok && v != nil && v != "" {
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDiff(t *testing.T) {
+	deployment := func(containers ...corev1.Container) *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.Spec.Template.Spec.Containers = containers
+		return d
+	}
+
+	before := deployment(
+		corev1.Container{
+			Name:  "app",
+			Image: "app:v1",
+			Env: []corev1.EnvVar{
+				{Name: "LOG_LEVEL", Value: "info"},
+				{Name: "PORT", Value: "8080"},
+			},
+		},
+		corev1.Container{Name: "sidecar", Image: "sidecar:v1"},
+	)
+	// reorder container and env, change sidecar
+	after := deployment(
+		corev1.Container{Name: "sidecar", Image: "sidecar:v2"},
+		corev1.Container{
+			Name:  "app",
+			Image: "app:v1",
+			Env: []corev1.EnvVar{
+				{Name: "PORT", Value: "8080"},
+				{Name: "LOG_LEVEL", Value: "debug"},
+			},
+		},
+	)
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "spec.template.spec.containers[sidecar].image", From: "sidecar:v1", To: "sidecar:v2"},
+		{Path: "spec.template.spec.containers[app].env[LOG_LEVEL].value", From: "info", To: "debug"},
+	}, changes)
+}
+
+func TestDiffList(t *testing.T) {
+	fixtures := []struct {
+		name     string
+		before   map[string]interface{}
+		after    map[string]interface{}
+		expected []Change
+	}{
+		{
+			"added",
+			map[string]interface{}{"volumes": []interface{}{}},
+			map[string]interface{}{"volumes": []interface{}{map[string]interface{}{"name": "data"}}},
+			[]Change{{Path: "volumes[data]", From: nil, To: map[string]interface{}{"name": "data"}}},
+		},
+		{
+			"removed",
+			map[string]interface{}{"tolerations": []interface{}{map[string]interface{}{"key": "gpu"}}},
+			map[string]interface{}{"tolerations": []interface{}{}},
+			[]Change{{Path: "tolerations[gpu]", From: map[string]interface{}{"key": "gpu"}, To: nil}},
+		},
+		{
+			"unknown by index",
+			map[string]interface{}{"args": []interface{}{"a", "b"}},
+			map[string]interface{}{"args": []interface{}{"a", "c", "d"}},
+			[]Change{{Path: "args.1", From: "b", To: "c"}, {Path: "args.2", From: nil, To: "d"}},
+		},
+		{
+			"duplicate key by index",
+			map[string]interface{}{"ports": []interface{}{
+				map[string]interface{}{"containerPort": 53, "protocol": "TCP"},
+				map[string]interface{}{"containerPort": 53, "protocol": "UDP"},
+			}},
+			map[string]interface{}{"ports": []interface{}{
+				map[string]interface{}{"containerPort": 53, "protocol": "TCP"},
+			}},
+			[]Change{{Path: "ports.1", From: map[string]interface{}{"containerPort": 53, "protocol": "UDP"}, To: nil}},
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			d := differ{}
+			d.diffMap("", fixture.before, fixture.after)
+			require.Equal(t, fixture.expected, d.changes)
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	deployment := func(replicas int32, image string, args ...string) *appsv1.Deployment {
+		d := &appsv1.Deployment{}
+		d.Spec.Replicas = &replicas
+		d.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: image, Args: args}}
+		return d
+	}
+	before := deployment(1, "app:v1", "--host=0.0.0.0", "--port=80", "--debug")
+	after := deployment(2, "app:v1", "--host=0.0.0.0", "--port=8080", "--debug")
+
+	changes, err := Diff(before, after)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+
+	hunks, err := Hunks(before, after, changes, nil)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "spec.replicas", From: float64(1), To: float64(2)},
+		{
+			Path: "spec.template.spec.containers[app].args",
+			From: []interface{}{"--host=0.0.0.0", "--port=80", "--debug"},
+			To:   []interface{}{"--host=0.0.0.0", "--port=8080", "--debug"},
+		},
+	}, hunks)
+
+	// NOTE: parent is not grouped if any sibling is excluded
+	after = deployment(1, "app:v2")
+	after.Spec.Template.Spec.Containers[0].WorkingDir = "/app"
+	changes, err = Diff(before, after)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	hunks, err = Hunks(before, after, changes[1:], changes[:1])
+	require.NoError(t, err)
+	require.Equal(t, changes[1:], hunks)
+}
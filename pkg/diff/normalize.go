@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	// quantityPath match field of resource.Quantity, e.g. resources.limits.memory
+	quantityPath = regexp.MustCompile(`(?:^|\.)(limits|requests|hard|capacity|allocatable)\.([^.]+)$|(?:^|\.)(sizeLimit|storage)$`)
+
+	// durationPath match field of duration, e.g. pause.duration of argo rollout, interval of flux,
+	// value of env or ConfigMap is free-form, so 60s and 1m may mean different things.
+	durationPath = regexp.MustCompile(`(?:^|\.)(duration|interval|timeout|period|delay|ttl|renewBefore|[a-z]+(Duration|Interval|Timeout|Period|Delay))$`)
+	// freeformPath match free-form map, which is never compared semantically.
+	freeformPath = regexp.MustCompile(`^(data|binaryData|stringData)\.|(?:^|\.)(annotations|labels)\.`)
+
+	// durationPattern match duration with unit, e.g. 1m, 60s, 1h30m
+	durationPattern = regexp.MustCompile(`^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`)
+)
+
+// normalize compare from and to semantically,
+// return equal if they are the same value in different representation,
+// e.g. 1Gi and 1024Mi, 500m and 0.5, 60s and 1m, 1 and "1" of IntOrString,
+// otherwise the human readable delta if available, e.g. memory limit +512Mi.
+func normalize(path string, from, to interface{}) (equal bool, delta string) {
+	if match := quantityPath.FindStringSubmatch(path); match != nil {
+		fq, ferr := toQuantity(from)
+		tq, terr := toQuantity(to)
+		if ferr != nil || terr != nil {
+			return false, ""
+		}
+		if fq.Cmp(tq) == 0 {
+			return true, ""
+		}
+
+		d := tq.DeepCopy()
+		d.Sub(fq)
+		sign := "+"
+		if d.Sign() < 0 {
+			sign = "-"
+			d.Neg()
+		}
+
+		name, kind := match[2], strings.TrimSuffix(match[1], "s")
+		if name == "" {
+			name, kind = match[3], ""
+		}
+		if kind == "" {
+			return false, fmt.Sprintf("%s %s%s", name, sign, d.String())
+		}
+		return false, fmt.Sprintf("%s %s %s%s", name, kind, sign, d.String())
+	}
+
+	fs, fok := from.(string)
+	ts, tok := to.(string)
+
+	// IntOrString, e.g. maxSurge: 1 vs "1"
+	if fok != tok {
+		s, n := fs, to
+		if tok {
+			s, n = ts, from
+		}
+		if f, ok := n.(float64); ok {
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil && float64(i) == f {
+				return true, ""
+			}
+		}
+		return false, ""
+	}
+
+	if fok && tok && durationPath.MatchString(path) && !freeformPath.MatchString(path) &&
+		durationPattern.MatchString(fs) && durationPattern.MatchString(ts) {
+		fd, ferr := time.ParseDuration(fs)
+		td, terr := time.ParseDuration(ts)
+		if ferr == nil && terr == nil && fd == td {
+			return true, ""
+		}
+	}
+
+	return false, ""
+}
+
+func toQuantity(v interface{}) (resource.Quantity, error) {
+	switch v := v.(type) {
+	case string:
+		return resource.ParseQuantity(v)
+	case float64:
+		return resource.ParseQuantity(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	return resource.Quantity{}, fmt.Errorf("unknown quantity: %v", v)
+}
@@ -0,0 +1,42 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	fixtures := []struct {
+		path  string
+		from  interface{}
+		to    interface{}
+		equal bool
+		delta string
+	}{
+		{"spec.containers[app].resources.limits.memory", "1Gi", "1024Mi", true, ""},
+		{"spec.containers[app].resources.requests.cpu", "500m", "0.5", true, ""},
+		{"spec.containers[app].resources.requests.cpu", "500m", 0.5, true, ""},
+		{"spec.containers[app].resources.limits.memory", "1Gi", "1536Mi", false, "memory limit +512Mi"},
+		{"spec.containers[app].resources.requests.cpu", "1", "500m", false, "cpu request -500m"},
+		{"spec.volumes[cache].emptyDir.sizeLimit", "1Gi", "2Gi", false, "sizeLimit +1Gi"},
+		{"spec.strategy.rollingUpdate.maxSurge", 1.0, "1", true, ""},
+		{"spec.strategy.rollingUpdate.maxSurge", 1.0, "25%", false, ""},
+		{"spec.strategy.canary.steps.0.pause.duration", "60s", "1m", true, ""},
+		{"spec.strategy.canary.steps.0.pause.duration", "60s", "2m", false, ""},
+		{"spec.progressTimeout", "1h", "60m", true, ""},
+		{"spec.containers[app].env[TIMEOUT].value", "60s", "1m", false, ""},
+		{"data.timeout", "60s", "1m", false, ""},
+		{"metadata.annotations.timeout", "60s", "1m", false, ""},
+		{"spec.containers[app].image", "app:1m", "app:60s", false, ""},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.path, func(t *testing.T) {
+			equal, delta := normalize(fixture.path, fixture.from, fixture.to)
+			require.Equal(t, fixture.equal, equal)
+			require.Equal(t, fixture.delta, delta)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// https://developers.dingtalk.com/document/robots/custom-robot-access
+type dingtalkMarkdown struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+type dingtalkMessage struct {
+	MsgType  string           `json:"msgtype"`
+	Markdown dingtalkMarkdown `json:"markdown"`
+}
+
+// DingtalkNotify post event as markdown to dingtalk custom robot,
+// sign the request if secret is not empty, body render text of markdown if not nil.
+func DingtalkNotify(addr string, secret string, timeFormat string, style DiffStyle, body Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		target := addr
+		if secret != "" {
+			var err error
+			target, err = dingtalkSignURL(addr, secret, time.Now().UnixNano()/int64(time.Millisecond))
+			if err != nil {
+				return err
+			}
+		}
+		return postJSON(target, dingtalkRender(e, timeFormat, style, body), errcodeCheck)
+	})
+}
+
+// DingtalksNotify parse secret from each webhook, see parseWebhook.
+func DingtalksNotify(webhooks []string, timeFormat string, style DiffStyle, body Formatter) (Notifier, error) {
+	hooks := make([]Notifier, len(webhooks))
+	for i, webhook := range webhooks {
+		addr, secret, err := parseWebhook(webhook)
+		if err != nil {
+			return nil, err
+		}
+		hooks[i] = DingtalkNotify(addr, secret, timeFormat, style, body)
+	}
+	return Multi(hooks...), nil
+}
+
+func dingtalkRender(e Event, timeFormat string, style DiffStyle, body Formatter) dingtalkMessage {
+	title := markdownTitle(e)
+	lines := append([]string{"#### " + title}, markdownBody(e, timeFormat, style, body)...)
+	return dingtalkMessage{
+		MsgType: "markdown",
+		Markdown: dingtalkMarkdown{
+			Title: title,
+			// dingtalk require two line breaks
+			Text: strings.Join(lines, "\n\n"),
+		},
+	}
+}
+
+// dingtalkSignURL use secret as key to sign timestamp + "\n" + secret,
+// timestamp is milliseconds.
+func dingtalkSignURL(addr string, secret string, ts int64) (string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", addr, err)
+	}
+
+	h := hmac.New(sha256.New, []byte(secret))
+	_, _ = h.Write([]byte(fmt.Sprintf("%d\n%s", ts, secret)))
+
+	query := u.Query()
+	query.Set("timestamp", strconv.FormatInt(ts, 10))
+	query.Set("sign", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// errcodeCheck verify {"errcode": 0, "errmsg": "ok"}, used by dingtalk and wecom.
+func errcodeCheck(body []byte) error {
+	resp := struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("json unmarshal: %w", err)
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("errcode(%d): %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDingtalkSignURL(t *testing.T) {
+	addr, secret, err := parseWebhook("https://oapi.dingtalk.com/robot/send?access_token=token#secret")
+	require.NoError(t, err)
+	require.Equal(t, "https://oapi.dingtalk.com/robot/send?access_token=token", addr)
+	require.Equal(t, "secret", secret)
+
+	signed, err := dingtalkSignURL(addr, secret, 1600000000000)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "token", u.Query().Get("access_token"))
+	require.Equal(t, "1600000000000", u.Query().Get("timestamp"))
+	require.Equal(t, "XHSnLTbboLLBCrXfAQRHx6W9LkLB43RYwgcsOS2j3vs=", u.Query().Get("sign"))
+}
+
+func TestErrcodeCheck(t *testing.T) {
+	require.NoError(t, errcodeCheck([]byte(`{"errcode":0,"errmsg":"ok"}`)))
+	require.Error(t, errcodeCheck([]byte(`{"errcode":310000,"errmsg":"sign not match"}`)))
+}
+
+func TestDingtalkRender(t *testing.T) {
+	e := Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated}
+	body, err := TemplateFormatter(`{{ define "Updated" }}{{ .Name }} is updated{{ end }}`, func(Event) string { return "" })
+	require.NoError(t, err)
+
+	msg := dingtalkRender(e, DefaultTimeFormat, DiffInline, body)
+	require.Equal(t, "#### Deployment default/app Updated\n\napp is updated", msg.Markdown.Text)
+
+	// no template for action
+	e.Action = Deleted
+	msg = dingtalkRender(e, DefaultTimeFormat, DiffInline, body)
+	require.Contains(t, msg.Markdown.Text, "**Deleted** at")
+}
@@ -0,0 +1,543 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/util"
+)
+
+type Action string
+
+const (
+	Created  Action = "Created"
+	Updated  Action = "Updated"
+	Deleted  Action = "Deleted"
+	NotReady Action = "NotReady"
+
+	RolloutStarted     Action = "RolloutStarted"
+	RolloutProgressing Action = "RolloutProgressing"
+	RolloutCompleted   Action = "RolloutCompleted"
+	RolloutFailed      Action = "RolloutFailed"
+
+	RolledBack Action = "RolledBack"
+
+	JobStarted   Action = "JobStarted"
+	JobSucceeded Action = "JobSucceeded"
+	JobFailed    Action = "JobFailed"
+
+	Scaled              Action = "Scaled"
+	AutoscalerPinned    Action = "AutoscalerPinned"
+	AutoscalerInactive  Action = "AutoscalerInactive"
+	AutoscalerRecovered Action = "AutoscalerRecovered"
+
+	PodCrashLooping     Action = "PodCrashLooping"
+	PodOOMKilled        Action = "PodOOMKilled"
+	PodRestarting       Action = "PodRestarting"
+	PodEvicted          Action = "PodEvicted"
+	PodImagePullBackOff Action = "PodImagePullBackOff"
+
+	NodeAdded            Action = "NodeAdded"
+	NodeRemoved          Action = "NodeRemoved"
+	NodeReady            Action = "NodeReady"
+	NodeNotReady         Action = "NodeNotReady"
+	NodePressure         Action = "NodePressure"
+	NodePressureRelieved Action = "NodePressureRelieved"
+	NodeCordoned         Action = "NodeCordoned"
+	NodeUncordoned       Action = "NodeUncordoned"
+	NodeTainted          Action = "NodeTainted"
+)
+
+// Actions are all known actions, e.g. to validate action of route.
+var Actions = map[Action]bool{
+	Created:  true,
+	Updated:  true,
+	Deleted:  true,
+	NotReady: true,
+
+	RolloutStarted:     true,
+	RolloutProgressing: true,
+	RolloutCompleted:   true,
+	RolloutFailed:      true,
+
+	RolledBack: true,
+
+	JobStarted:   true,
+	JobSucceeded: true,
+	JobFailed:    true,
+
+	Scaled:              true,
+	AutoscalerPinned:    true,
+	AutoscalerInactive:  true,
+	AutoscalerRecovered: true,
+
+	PodCrashLooping:     true,
+	PodOOMKilled:        true,
+	PodRestarting:       true,
+	PodEvicted:          true,
+	PodImagePullBackOff: true,
+
+	NodeAdded:            true,
+	NodeRemoved:          true,
+	NodeReady:            true,
+	NodeNotReady:         true,
+	NodePressure:         true,
+	NodePressureRelieved: true,
+	NodeCordoned:         true,
+	NodeUncordoned:       true,
+	NodeTainted:          true,
+}
+
+// PodActions are pod health actions, reported against the top-level owner of pod.
+var PodActions = map[Action]bool{
+	PodCrashLooping:     true,
+	PodOOMKilled:        true,
+	PodRestarting:       true,
+	PodEvicted:          true,
+	PodImagePullBackOff: true,
+}
+
+// Change is a single field change, Path is joined by dot.
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+	// Delta is the human readable delta, e.g. memory limit +512Mi
+	Delta string `json:"delta,omitempty"`
+	// Manager and Operation of managedFields which own the field, e.g. kubectl-edit and Update,
+	// or its parent if the field is removed
+	Manager   string `json:"manager,omitempty"`
+	Operation string `json:"operation,omitempty"`
+}
+
+func (c Change) String() string {
+	if c.Delta == "" {
+		return fmt.Sprintf("%s(%v - %v)", c.Path, c.From, c.To)
+	}
+	return fmt.Sprintf("%s(%v - %v, %s)", c.Path, c.From, c.To, c.Delta)
+}
+
+// PodStatus describe why a pod is not running.
+type PodStatus struct {
+	Name   string `json:"name"`
+	Phase  string `json:"phase"`
+	Reason string `json:"reason"`
+	// Node, only for DaemonSet and Pod*
+	Node string `json:"node,omitempty"`
+
+	// Restarts and Message, only for Pod*
+	Restarts int32  `json:"restarts,omitempty"`
+	Message  string `json:"message,omitempty"`
+
+	// Log, tail of the failing container log, only if enabled,
+	// not rendered by TextFormatter since it is multiple lines
+	Log string `json:"log,omitempty"`
+}
+
+func (p PodStatus) String() string {
+	if p.Node == "" {
+		return fmt.Sprintf("%s(%s)", p.Phase, p.Reason)
+	}
+	return fmt.Sprintf("%s(%s)@%s", p.Phase, p.Reason, p.Node)
+}
+
+// Warning is the warning events of the same reason, e.g. FailedScheduling,
+// of workload, its ReplicaSet and pods.
+type Warning struct {
+	Reason string `json:"reason"`
+	// Message, the latest one
+	Message string `json:"message"`
+	Count   int32  `json:"count"`
+	// Object, the latest one reported, e.g. Pod/app-5d4f8-x1
+	Object string `json:"object"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s x%d: %s", w.Reason, w.Count, w.Message)
+}
+
+// Rollout is the progress of a workload rollout.
+type Rollout struct {
+	Generation int64         `json:"generation"`
+	Desired    int32         `json:"desired"`
+	Updated    int32         `json:"updated"`
+	Ready      int32         `json:"ready"`
+	Available  int32         `json:"available"`
+	Duration   time.Duration `json:"duration"`
+	Reason     string        `json:"reason,omitempty"`
+	Message    string        `json:"message,omitempty"`
+
+	// Strategy, Partition and Revision, only for StatefulSet
+	Strategy        string `json:"strategy,omitempty"`
+	Partition       int32  `json:"partition,omitempty"`
+	CurrentRevision string `json:"currentRevision,omitempty"`
+	UpdateRevision  string `json:"updateRevision,omitempty"`
+	// Blocking, pods block the rollout
+	Blocking []string `json:"blocking,omitempty"`
+}
+
+// Progress return percent of updated replicas.
+func (r Rollout) Progress() int {
+	if r.Desired <= 0 {
+		return 100
+	}
+	return int(r.Updated * 100 / r.Desired)
+}
+
+// Rollback is the revision and images rollback from and to.
+type Rollback struct {
+	FromRevision string   `json:"fromRevision"`
+	ToRevision   string   `json:"toRevision"`
+	FromImages   []string `json:"fromImages"`
+	ToImages     []string `json:"toImages"`
+}
+
+// Job is the status of Job, or the schedule of CronJob.
+type Job struct {
+	// Schedule, Suspend and LastSchedule, only for CronJob
+	Schedule     string    `json:"schedule,omitempty"`
+	Suspend      bool      `json:"suspend,omitempty"`
+	LastSchedule time.Time `json:"lastSchedule,omitempty"`
+
+	// CronJob is the name of CronJob which created the Job
+	CronJob      string        `json:"cronJob,omitempty"`
+	Completions  int32         `json:"completions,omitempty"`
+	Active       int32         `json:"active,omitempty"`
+	Succeeded    int32         `json:"succeeded,omitempty"`
+	Failed       int32         `json:"failed,omitempty"`
+	BackoffLimit int32         `json:"backoffLimit,omitempty"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+	Message      string        `json:"message,omitempty"`
+}
+
+// Scaling is the replicas change of workload, or the state of HorizontalPodAutoscaler.
+type Scaling struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+	// By, e.g. HorizontalPodAutoscaler/app, or field manager if Manual, e.g. kubectl,
+	// and target of HorizontalPodAutoscaler for Autoscaler*, e.g. Deployment/app
+	By     string `json:"by,omitempty"`
+	Manual bool   `json:"manual,omitempty"`
+
+	// Min, Max and Metrics, only by HorizontalPodAutoscaler, metric is like cpu 85%/70%
+	Min     int32    `json:"min,omitempty"`
+	Max     int32    `json:"max,omitempty"`
+	Metrics []string `json:"metrics,omitempty"`
+
+	// Reason and Message, only for AutoscalerPinned and AutoscalerInactive
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Node is the state of Node, and workloads whose pods are on it.
+type Node struct {
+	// Conditions, abnormal conditions of node, e.g. NotReady and MemoryPressure
+	Conditions    []string `json:"conditions,omitempty"`
+	Unschedulable bool     `json:"unschedulable,omitempty"`
+
+	// Added and Removed, taints changed, e.g. dedicated=infra:NoSchedule
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+
+	// Reason and Message of the condition changed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Workloads, top-level owner of pods on the node, e.g. DaemonSet/kube-system/fluentd
+	Workloads []string `json:"workloads,omitempty"`
+}
+
+// ImageChange summary the image change of a container.
+type ImageChange struct {
+	Container string `json:"container"`
+	// Path of the change, e.g. spec.template.spec.containers[app].image
+	Path string `json:"path"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Type, one of Tag, Digest, Repository and Registry
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+	Link    string `json:"link,omitempty"`
+}
+
+type Event struct {
+	Kind            string            `json:"kind"`
+	Namespace       string            `json:"namespace"`
+	Name            string            `json:"name"`
+	UID             string            `json:"uid"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Action          Action            `json:"action"`
+	ResourceVersion string            `json:"resourceVersion"`
+	Timestamp       time.Time         `json:"timestamp"`
+
+	// Changes and Images, only for Updated and RolledBack
+	Changes []Change      `json:"changes,omitempty"`
+	Images  []ImageChange `json:"images,omitempty"`
+	// Managers made the changes, e.g. kubectl-edit, see Change.Manager
+	Managers []string `json:"managers,omitempty"`
+	// Hunks is changes grouped by their parent map or list, except images, rendered by DiffUnified
+	Hunks []Change `json:"-"`
+
+	// Age, Desired, Ready and Pods, only for NotReady and RolloutFailed,
+	// and Pods for JobFailed and Pod* too
+	Age     time.Duration `json:"age"`
+	Desired int32         `json:"desired"`
+	Ready   int32         `json:"ready"`
+	Pods    []PodStatus   `json:"pods,omitempty"`
+
+	// Warnings, recent warning events, only for RolloutFailed, JobFailed and Pod*
+	Warnings []Warning `json:"warnings,omitempty"`
+
+	// Rollout, only for Rollout*
+	Rollout *Rollout `json:"rollout,omitempty"`
+
+	// Rollback, only for Rollback
+	Rollback *Rollback `json:"rollback,omitempty"`
+
+	// Job, only for CronJob and Job*
+	Job *Job `json:"job,omitempty"`
+
+	// Scaling, only for Scaled and Autoscaler*
+	Scaling *Scaling `json:"scaling,omitempty"`
+
+	// Node, only for Node*
+	Node *Node `json:"node,omitempty"`
+
+	// Consumers, workloads reference the ConfigMap or Secret, e.g. Deployment/app
+	Consumers []string `json:"consumers,omitempty"`
+}
+
+// Key return namespace/name, or name if cluster scoped.
+func (e Event) Key() string {
+	if e.Namespace == "" {
+		return e.Name
+	}
+	return e.Namespace + "/" + e.Name
+}
+
+func (e Event) String() string {
+	return TextFormatter(DefaultTimeFormat, false)(e)
+}
+
+const DefaultTimeFormat = "15:04:05Z07:00"
+
+// Formatter render event as a message.
+type Formatter func(Event) string
+
+// TextFormatter render event as a single line,
+// append ResourceVersion if verbose.
+func TextFormatter(timeFormat string, verbose bool) Formatter {
+	return func(e Event) string {
+		msgs := []string{}
+		switch e.Action {
+		case Created:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) CreatedAt(%s)",
+				e.Kind, e.Key(), e.Timestamp.Format(timeFormat)))
+		case Deleted:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) DeletedAt(%s)",
+				e.Kind, e.Key(), e.Timestamp.Format(timeFormat)))
+		case Updated:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) ChangedAt(%s)",
+				e.Kind, e.Key(), e.Timestamp.Format(timeFormat)))
+			if len(e.Managers) > 0 {
+				msgs = append(msgs, fmt.Sprintf("By(%s)", strings.Join(e.Managers, ",")))
+			}
+			msgs = append(msgs, textChanges(e)...)
+		case RolledBack:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) RolledBackAt(%s)",
+				e.Kind, e.Key(), e.Timestamp.Format(timeFormat)))
+			if len(e.Managers) > 0 {
+				msgs = append(msgs, fmt.Sprintf("By(%s)", strings.Join(e.Managers, ",")))
+			}
+			if r := e.Rollback; r != nil {
+				msgs = append(msgs,
+					fmt.Sprintf("Revision(%s - %s)", r.FromRevision, r.ToRevision),
+					fmt.Sprintf("Images(%s - %s)", strings.Join(r.FromImages, ","), strings.Join(r.ToImages, ",")))
+			}
+			msgs = append(msgs, textChanges(e)...)
+		case NotReady:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) Age(%s) READY(%d/%d)",
+				e.Kind, e.Key(), util.PrettyDuration(e.Age, 2), e.Ready, e.Desired))
+			for _, pod := range e.Pods {
+				msgs = append(msgs, pod.String())
+			}
+		default:
+			msgs = append(msgs, fmt.Sprintf(
+				"%s(%s) %s(%s)",
+				e.Kind, e.Key(), e.Action, e.Timestamp.Format(timeFormat)))
+		}
+
+		if r := e.Rollout; r != nil {
+			switch e.Action {
+			case RolloutStarted:
+				msgs = append(msgs, fmt.Sprintf("Generation(%d) UPDATED(%d/%d)", r.Generation, r.Updated, r.Desired))
+				if r.Partition > 0 {
+					msgs = append(msgs, fmt.Sprintf("PARTITION(%d)", r.Partition))
+				}
+			case RolloutProgressing:
+				msgs = append(msgs, fmt.Sprintf("UPDATED(%d/%d) %d%%", r.Updated, r.Desired, r.Progress()))
+			case RolloutCompleted:
+				msgs = append(msgs, fmt.Sprintf("Duration(%s)", util.PrettyDuration(r.Duration, 2)))
+			case RolloutFailed:
+				msgs = append(msgs, fmt.Sprintf(
+					"Duration(%s) READY(%d/%d) Reason(%s)",
+					util.PrettyDuration(r.Duration, 2), r.Ready, r.Desired, r.Reason))
+				if len(r.Blocking) > 0 {
+					msgs = append(msgs, fmt.Sprintf("BLOCKING(%s)", strings.Join(r.Blocking, ",")))
+				}
+				for _, pod := range e.Pods {
+					msgs = append(msgs, pod.String())
+				}
+			}
+		}
+
+		if j := e.Job; j != nil {
+			msgs = append(msgs, textJob(e.Action, j)...)
+			if e.Action == JobFailed {
+				for _, pod := range e.Pods {
+					msgs = append(msgs, pod.String())
+				}
+			}
+		}
+
+		if s := e.Scaling; s != nil {
+			msgs = append(msgs, textScaling(e.Action, s)...)
+		}
+
+		if PodActions[e.Action] {
+			for _, pod := range e.Pods {
+				msgs = append(msgs, textPod(pod))
+			}
+		}
+
+		if n := e.Node; n != nil {
+			msgs = append(msgs, textNode(n)...)
+		}
+
+		for _, w := range e.Warnings {
+			msgs = append(msgs, fmt.Sprintf("Warning(%s)", w))
+		}
+
+		if len(e.Consumers) > 0 {
+			msgs = append(msgs, fmt.Sprintf("Consumers(%s)", strings.Join(e.Consumers, ",")))
+		}
+
+		if verbose {
+			msgs = append(msgs, fmt.Sprintf("ResourceVersion(%s)", e.ResourceVersion))
+		}
+
+		return strings.Join(msgs, " ")
+	}
+}
+
+func textJob(action Action, j *Job) []string {
+	msgs := []string{}
+	if j.Schedule != "" {
+		msgs = append(msgs, fmt.Sprintf("Schedule(%s)", j.Schedule))
+		if j.Suspend {
+			msgs = append(msgs, "Suspend")
+		}
+		if !j.LastSchedule.IsZero() {
+			msgs = append(msgs, fmt.Sprintf("LastSchedule(%s)", j.LastSchedule.Format(time.RFC3339)))
+		}
+	}
+	if j.CronJob != "" {
+		msgs = append(msgs, fmt.Sprintf("CronJob(%s)", j.CronJob))
+	}
+	switch action {
+	case JobStarted:
+		msgs = append(msgs, fmt.Sprintf("COMPLETIONS(%d/%d)", j.Succeeded, j.Completions))
+	case JobSucceeded:
+		msgs = append(msgs, fmt.Sprintf(
+			"Duration(%s) COMPLETIONS(%d/%d)",
+			util.PrettyDuration(j.Duration, 2), j.Succeeded, j.Completions))
+	case JobFailed:
+		msgs = append(msgs, fmt.Sprintf(
+			"Duration(%s) FAILED(%d/%d) Reason(%s)",
+			util.PrettyDuration(j.Duration, 2), j.Failed, j.BackoffLimit, j.Reason))
+	}
+	return msgs
+}
+
+func textScaling(action Action, s *Scaling) []string {
+	msgs := []string{fmt.Sprintf("REPLICAS(%d - %d)", s.From, s.To)}
+	if s.By != "" {
+		msgs = append(msgs, fmt.Sprintf("By(%s)", s.By))
+	}
+	if s.Max > 0 {
+		msgs = append(msgs, fmt.Sprintf("Range(%d-%d)", s.Min, s.Max))
+	}
+	if len(s.Metrics) > 0 {
+		msgs = append(msgs, fmt.Sprintf("Metrics(%s)", strings.Join(s.Metrics, ",")))
+	}
+	if s.Reason != "" && action != Scaled {
+		msgs = append(msgs, fmt.Sprintf("Reason(%s)", s.Reason))
+	}
+	return msgs
+}
+
+func textNode(n *Node) []string {
+	msgs := []string{}
+	if n.Reason != "" {
+		msgs = append(msgs, fmt.Sprintf("Reason(%s)", n.Reason))
+	}
+	if len(n.Conditions) > 0 {
+		msgs = append(msgs, fmt.Sprintf("Conditions(%s)", strings.Join(n.Conditions, ",")))
+	}
+	if n.Unschedulable {
+		msgs = append(msgs, "Unschedulable")
+	}
+	taints := []string{}
+	for _, taint := range n.Added {
+		taints = append(taints, "+"+taint)
+	}
+	for _, taint := range n.Removed {
+		taints = append(taints, "-"+taint)
+	}
+	if len(taints) > 0 {
+		msgs = append(msgs, fmt.Sprintf("Taints(%s)", strings.Join(taints, ",")))
+	}
+	if len(n.Workloads) > 0 {
+		msgs = append(msgs, fmt.Sprintf("Workloads(%s)", strings.Join(n.Workloads, ",")))
+	}
+	return msgs
+}
+
+func textPod(pod PodStatus) string {
+	msg := fmt.Sprintf("Pod(%s) %s", pod.Name, pod)
+	if pod.Restarts > 0 {
+		msg += fmt.Sprintf(" Restarts(%d)", pod.Restarts)
+	}
+	if pod.Message != "" {
+		msg += fmt.Sprintf(" Message(%s)", pod.Message)
+	}
+	return msg
+}
+
+// textChanges render image summary instead of the raw change of image.
+func textChanges(e Event) []string {
+	msgs := []string{}
+	paths := map[string]bool{}
+	for _, image := range e.Images {
+		paths[image.Path] = true
+		msg := fmt.Sprintf("Image(%s)", image.Summary)
+		if image.Link != "" {
+			msg = fmt.Sprintf("Image(%s %s)", image.Summary, image.Link)
+		}
+		msgs = append(msgs, msg)
+	}
+	for _, change := range e.Changes {
+		if paths[change.Path] {
+			continue
+		}
+		msgs = append(msgs, change.String())
+	}
+	return msgs
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextFormatter(t *testing.T) {
+	ts := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		name     string
+		e        Event
+		expected string
+	}{
+		{
+			"created",
+			Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Created, Timestamp: ts},
+			"Deployment(default/app) CreatedAt(08:00:00Z)",
+		},
+		{
+			"updated",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated, Timestamp: ts,
+				Changes: []Change{{Path: "spec.replicas", From: 1, To: 2}},
+			},
+			"Deployment(default/app) ChangedAt(08:00:00Z) spec.replicas(1 - 2)",
+		},
+		{
+			"updated by",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated, Timestamp: ts,
+				Changes: []Change{
+					{Path: "spec.template.spec.containers[app].resources.limits.memory", From: "1Gi", To: "2Gi",
+						Manager: "kubectl-edit", Operation: "Update"},
+				},
+				Managers: []string{"kubectl-edit"},
+			},
+			"Deployment(default/app) ChangedAt(08:00:00Z) By(kubectl-edit) " +
+				"spec.template.spec.containers[app].resources.limits.memory(1Gi - 2Gi)",
+		},
+		{
+			"image",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated, Timestamp: ts,
+				Changes: []Change{
+					{Path: "spec.template.spec.containers[app].image", From: "app:v1", To: "app:v2"},
+					{Path: "spec.replicas", From: 1, To: 2},
+				},
+				Images: []ImageChange{
+					{Container: "app", Path: "spec.template.spec.containers[app].image", Type: "Tag", Summary: "app: v1 → v2"},
+				},
+			},
+			"Deployment(default/app) ChangedAt(08:00:00Z) Image(app: v1 → v2) spec.replicas(1 - 2)",
+		},
+		{
+			"notready",
+			Event{
+				Kind: "StatefulSet", Namespace: "default", Name: "db", Action: NotReady,
+				Age: 90 * time.Second, Desired: 3, Ready: 1,
+				Pods: []PodStatus{{Name: "db-1", Phase: "Pending", Reason: "app[ImagePullBackOff]"}},
+			},
+			"StatefulSet(default/db) Age(1m30s) READY(1/3) Pending(app[ImagePullBackOff])",
+		},
+		{
+			"job failed",
+			Event{
+				Kind: "Job", Namespace: "batch", Name: "report-1625126400", Action: JobFailed, Timestamp: ts,
+				Job: &Job{
+					CronJob: "report", Completions: 1, Failed: 3, BackoffLimit: 2,
+					Duration: 5 * time.Minute, Reason: "BackoffLimitExceeded",
+				},
+				Pods: []PodStatus{{Name: "report-1625126400-x1", Phase: "Failed", Reason: "app[OOMKilled]"}},
+			},
+			"Job(batch/report-1625126400) JobFailed(08:00:00Z) CronJob(report) " +
+				"Duration(5m0s) FAILED(3/2) Reason(BackoffLimitExceeded) Failed(app[OOMKilled])",
+		},
+		{
+			"configmap",
+			Event{
+				Kind: "ConfigMap", Namespace: "default", Name: "app-config", Action: Updated, Timestamp: ts,
+				Changes:   []Change{{Path: "data.LOG_LEVEL", From: "info", To: "debug"}},
+				Consumers: []string{"CronJob/report", "Deployment/app"},
+			},
+			"ConfigMap(default/app-config) ChangedAt(08:00:00Z) data.LOG_LEVEL(info - debug) " +
+				"Consumers(CronJob/report,Deployment/app)",
+		},
+		{
+			"cronjob",
+			Event{
+				Kind: "CronJob", Namespace: "batch", Name: "report", Action: Updated, Timestamp: ts,
+				Changes: []Change{{Path: "spec.suspend", From: false, To: true}},
+				Job:     &Job{Schedule: "0 * * * *", Suspend: true, LastSchedule: ts},
+			},
+			"CronJob(batch/report) ChangedAt(08:00:00Z) spec.suspend(false - true) " +
+				"Schedule(0 * * * *) Suspend LastSchedule(2021-07-01T08:00:00Z)",
+		},
+		{
+			"pod oomkilled",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "app", Action: PodOOMKilled, Timestamp: ts,
+				Pods: []PodStatus{{
+					Name: "app-5d4f8-x1", Phase: "Running", Reason: "app[OOMKilled]",
+					Node: "node-1", Restarts: 3, Message: "exit code 137",
+				}},
+			},
+			"Deployment(default/app) PodOOMKilled(08:00:00Z) " +
+				"Pod(app-5d4f8-x1) Running(app[OOMKilled])@node-1 Restarts(3) Message(exit code 137)",
+		},
+		{
+			"node notready",
+			Event{
+				Kind: "Node", Name: "node-1", Action: NodeNotReady, Timestamp: ts,
+				Node: &Node{
+					Conditions: []string{"NotReady"}, Reason: "NodeStatusUnknown",
+					Workloads: []string{"DaemonSet/kube-system/fluentd", "StatefulSet/default/db"},
+				},
+			},
+			"Node(node-1) NodeNotReady(08:00:00Z) Reason(NodeStatusUnknown) Conditions(NotReady) " +
+				"Workloads(DaemonSet/kube-system/fluentd,StatefulSet/default/db)",
+		},
+		{
+			"node tainted",
+			Event{
+				Kind: "Node", Name: "node-1", Action: NodeTainted, Timestamp: ts,
+				Node: &Node{Unschedulable: true, Added: []string{"dedicated=infra:NoSchedule"}, Removed: []string{"gpu:NoExecute"}},
+			},
+			"Node(node-1) NodeTainted(08:00:00Z) Unschedulable Taints(+dedicated=infra:NoSchedule,-gpu:NoExecute)",
+		},
+		{
+			"pod warnings",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "app", Action: PodCrashLooping, Timestamp: ts,
+				Pods: []PodStatus{{Name: "app-5d4f8-x1", Phase: "Running", Reason: "app[CrashLoopBackOff]", Restarts: 4}},
+				Warnings: []Warning{
+					{Reason: "Unhealthy", Message: "Liveness probe failed", Count: 12, Object: "Pod/app-5d4f8-x1"},
+				},
+			},
+			"Deployment(default/app) PodCrashLooping(08:00:00Z) " +
+				"Pod(app-5d4f8-x1) Running(app[CrashLoopBackOff]) Restarts(4) " +
+				"Warning(Unhealthy x12: Liveness probe failed)",
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			actual := TextFormatter(DefaultTimeFormat, false)(fixture.e)
+			require.Equal(t, fixture.expected, actual)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	// NOTE: every action has a color, so that new action is added to Actions as well
+	require.Len(t, Actions, len(slackColors))
+	for action := range slackColors {
+		require.True(t, Actions[action], action)
+	}
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/rs/zerolog/log"
+)
+
+type KafkaNotifier struct {
+	producer sarama.AsyncProducer
+	topic    string
+
+	onError func(*sarama.ProducerError)
+	done    chan struct{}
+
+	// mu protect producer from sending after closed
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ Notifier = (*KafkaNotifier)(nil)
+
+// KafkaNotify send event as json to topic, keyed by kind/namespace/name.
+// Delivery error is reported to onError, default log it.
+func KafkaNotify(producer sarama.AsyncProducer, topic string, onError func(*sarama.ProducerError)) *KafkaNotifier {
+	if onError == nil {
+		onError = func(err *sarama.ProducerError) {
+			key, _ := err.Msg.Key.Encode()
+			log.Warn().Err(err.Err).Msgf("send %s to kafka(%s)", string(key), err.Msg.Topic)
+		}
+	}
+
+	n := &KafkaNotifier{
+		producer: producer,
+		topic:    topic,
+		onError:  onError,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(n.done)
+		for err := range producer.Errors() {
+			n.onError(err)
+		}
+	}()
+
+	return n
+}
+
+func (n *KafkaNotifier) Notify(e Event) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return ErrClosed
+	}
+
+	n.producer.Input() <- &sarama.ProducerMessage{
+		Topic: n.topic,
+		Key:   sarama.StringEncoder(e.Kind + "/" + e.Key()),
+		Value: sarama.ByteEncoder(value),
+	}
+	return nil
+}
+
+// Close flush pending messages and wait all errors are reported.
+func (n *KafkaNotifier) Close() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.mu.Unlock()
+
+	n.producer.AsyncClose()
+	<-n.done
+	return nil
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaNotify(t *testing.T) {
+	producer := mocks.NewAsyncProducer(t, nil)
+
+	producer.ExpectInputWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		key, _ := msg.Key.Encode()
+		if string(key) != "Deployment/default/app" {
+			return fmt.Errorf("unexpected key: %s", string(key))
+		}
+		value, _ := msg.Value.Encode()
+		e := Event{}
+		if err := json.Unmarshal(value, &e); err != nil {
+			return err
+		}
+		if e.Action != Updated || len(e.Changes) != 1 {
+			return fmt.Errorf("unexpected event: %s", string(value))
+		}
+		return nil
+	})
+	producer.ExpectInputAndFail(sarama.ErrOutOfBrokers)
+
+	failed := []error{}
+	n := KafkaNotify(producer, "kubenotify", func(err *sarama.ProducerError) {
+		failed = append(failed, err.Err)
+	})
+
+	e := Event{
+		Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated,
+		Changes: []Change{{Path: "spec.replicas", From: 1, To: 2}},
+	}
+	require.NoError(t, n.Notify(e))
+	require.NoError(t, n.Notify(e))
+	require.NoError(t, n.Close())
+	require.True(t, errors.Is(n.Notify(e), ErrClosed))
+
+	require.Len(t, failed, 1)
+	require.True(t, errors.Is(failed[0], sarama.ErrOutOfBrokers))
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// https://open.feishu.cn/document/ukTMukTMukTM/ucTM5YjL3ETO24yNxkjN
+type larkText struct {
+	Tag     string `json:"tag"`
+	Content string `json:"content"`
+}
+
+type larkElement struct {
+	Tag  string    `json:"tag"`
+	Text *larkText `json:"text,omitempty"`
+}
+
+type larkHeader struct {
+	Title    larkText `json:"title"`
+	Template string   `json:"template"`
+}
+
+type larkCard struct {
+	Config   map[string]bool `json:"config"`
+	Header   larkHeader      `json:"header"`
+	Elements []larkElement   `json:"elements"`
+}
+
+type larkMessage struct {
+	Timestamp string   `json:"timestamp,omitempty"`
+	Sign      string   `json:"sign,omitempty"`
+	MsgType   string   `json:"msg_type"`
+	Card      larkCard `json:"card"`
+}
+
+var larkTemplates = map[Action]string{
+	Created:  "green",
+	Updated:  "blue",
+	Deleted:  "orange",
+	NotReady: "red",
+
+	RolloutStarted:     "blue",
+	RolloutProgressing: "blue",
+	RolloutCompleted:   "green",
+	RolloutFailed:      "red",
+
+	RolledBack: "orange",
+
+	JobStarted:   "blue",
+	JobSucceeded: "green",
+	JobFailed:    "red",
+
+	Scaled:              "blue",
+	AutoscalerPinned:    "orange",
+	AutoscalerInactive:  "red",
+	AutoscalerRecovered: "green",
+
+	PodCrashLooping:     "red",
+	PodOOMKilled:        "red",
+	PodRestarting:       "orange",
+	PodEvicted:          "orange",
+	PodImagePullBackOff: "red",
+
+	NodeAdded:            "green",
+	NodeRemoved:          "orange",
+	NodeReady:            "green",
+	NodeNotReady:         "red",
+	NodePressure:         "red",
+	NodePressureRelieved: "green",
+	NodeCordoned:         "orange",
+	NodeUncordoned:       "green",
+	NodeTainted:          "orange",
+}
+
+// LarkNotify post event as interactive card to lark/feishu custom bot,
+// sign the message if secret is not empty, body render content of card if not nil.
+func LarkNotify(addr string, secret string, timeFormat string, style DiffStyle, body Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		msg := larkRender(e, timeFormat, style, body)
+		if secret != "" {
+			ts := time.Now().Unix()
+			msg.Timestamp = strconv.FormatInt(ts, 10)
+			msg.Sign = larkSign(secret, ts)
+		}
+		return postJSON(addr, msg, larkCheck)
+	})
+}
+
+// LarksNotify parse secret from each webhook, see parseWebhook.
+func LarksNotify(webhooks []string, timeFormat string, style DiffStyle, body Formatter) (Notifier, error) {
+	hooks := make([]Notifier, len(webhooks))
+	for i, webhook := range webhooks {
+		addr, secret, err := parseWebhook(webhook)
+		if err != nil {
+			return nil, err
+		}
+		hooks[i] = LarkNotify(addr, secret, timeFormat, style, body)
+	}
+	return Multi(hooks...), nil
+}
+
+func larkRender(e Event, timeFormat string, style DiffStyle, body Formatter) larkMessage {
+	template, ok := larkTemplates[e.Action]
+	if !ok {
+		template = "grey"
+	}
+	return larkMessage{
+		MsgType: "interactive",
+		Card: larkCard{
+			Config: map[string]bool{"wide_screen_mode": true},
+			Header: larkHeader{
+				Title:    larkText{Tag: "plain_text", Content: markdownTitle(e)},
+				Template: template,
+			},
+			Elements: []larkElement{{
+				Tag: "div",
+				Text: &larkText{
+					Tag:     "lark_md",
+					Content: strings.Join(markdownBody(e, timeFormat, style, body), "\n"),
+				},
+			}},
+		},
+	}
+}
+
+// larkSign use timestamp + "\n" + secret as key to sign empty string.
+func larkSign(secret string, ts int64) string {
+	key := fmt.Sprintf("%d\n%s", ts, secret)
+	h := hmac.New(sha256.New, []byte(key))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func larkCheck(body []byte) error {
+	resp := struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("json unmarshal: %w", err)
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("lark error(%d): %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
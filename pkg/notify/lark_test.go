@@ -0,0 +1,16 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLarkSign(t *testing.T) {
+	require.Equal(t, "vvU1S4ucHy95pQ90meMW66yQJ+Szge4s9g7hQUu9yP8=", larkSign("secret", 1600000000))
+}
+
+func TestLarkCheck(t *testing.T) {
+	require.NoError(t, larkCheck([]byte(`{"code":0,"msg":"success"}`)))
+	require.Error(t, larkCheck([]byte(`{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`)))
+}
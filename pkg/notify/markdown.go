@@ -0,0 +1,213 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/util"
+)
+
+// markdownTitle is the one line summary of event.
+func markdownTitle(e Event) string {
+	return fmt.Sprintf("%s %s %s", e.Kind, e.Key(), e.Action)
+}
+
+// markdownBody render event by body if set and not empty, e.g. template of user,
+// otherwise by markdownRender.
+func markdownBody(e Event, timeFormat string, style DiffStyle, body Formatter) []string {
+	if body != nil {
+		if text := body(e); text != "" {
+			return []string{text}
+		}
+	}
+	return markdownRender(e, timeFormat, style)
+}
+
+// markdownRender render event as markdown lines,
+// which is shared by lark, dingtalk and wecom.
+func markdownRender(e Event, timeFormat string, style DiffStyle) []string {
+	lines := []string{
+		fmt.Sprintf("**%s** at %s", e.Action, e.Timestamp.Format(timeFormat)),
+	}
+	if len(e.Managers) > 0 {
+		lines[0] += fmt.Sprintf(" by **%s**", strings.Join(e.Managers, ", "))
+	}
+	if r := e.Rollback; r != nil {
+		lines = append(lines,
+			fmt.Sprintf("Revision **%s** → **%s**", r.FromRevision, r.ToRevision),
+			fmt.Sprintf("Images `%s` → `%s`", strings.Join(r.FromImages, ", "), strings.Join(r.ToImages, ", ")))
+	}
+
+	switch e.Action {
+	case Updated, RolledBack:
+		paths := map[string]bool{}
+		for _, image := range e.Images {
+			paths[image.Path] = true
+			if image.Link != "" {
+				lines = append(lines, fmt.Sprintf("- **%s** [%s](%s)", image.Summary, image.Type, image.Link))
+			} else {
+				lines = append(lines, fmt.Sprintf("- **%s**", image.Summary))
+			}
+		}
+		if style == DiffUnified {
+			if diff := unifiedDiff(unifiedChanges(e)); diff != "" {
+				lines = append(lines, "```diff\n"+diff+"\n```")
+			}
+			break
+		}
+		for _, change := range e.Changes {
+			if paths[change.Path] {
+				continue
+			}
+			line := fmt.Sprintf("- **%s**: `%v` → `%v`", change.Path, change.From, change.To)
+			if change.Delta != "" {
+				line = fmt.Sprintf("%s (%s)", line, change.Delta)
+			}
+			lines = append(lines, line)
+		}
+	case NotReady:
+		lines = append(lines, fmt.Sprintf(
+			"Age **%s** Ready **%d/%d**", util.PrettyDuration(e.Age, 2), e.Ready, e.Desired))
+		for _, pod := range e.Pods {
+			lines = append(lines, markdownLog(fmt.Sprintf("- **%s**: %s", pod.Name, pod), pod.Log))
+		}
+	}
+
+	if r := e.Rollout; r != nil {
+		lines = append(lines, fmt.Sprintf(
+			"Generation **%d** Updated **%d/%d** (%d%%) Ready **%d/%d**",
+			r.Generation, r.Updated, r.Desired, r.Progress(), r.Ready, r.Desired))
+		if r.Partition > 0 {
+			lines = append(lines, fmt.Sprintf("Partition **%d**, %d of %d pods on new revision", r.Partition, r.Updated, r.Desired))
+		}
+		if r.Duration > 0 {
+			lines = append(lines, fmt.Sprintf("Duration **%s**", util.PrettyDuration(r.Duration, 2)))
+		}
+		if len(r.Blocking) > 0 {
+			lines = append(lines, fmt.Sprintf("Blocking **%s**", strings.Join(r.Blocking, ", ")))
+		}
+		if r.Reason != "" {
+			lines = append(lines, fmt.Sprintf("Reason **%s**: %s", r.Reason, r.Message))
+		}
+		if e.Action == RolloutFailed {
+			for _, pod := range e.Pods {
+				lines = append(lines, markdownLog(fmt.Sprintf("- **%s**: %s", pod.Name, pod), pod.Log))
+			}
+		}
+	}
+
+	if j := e.Job; j != nil {
+		lines = append(lines, markdownJob(e, j)...)
+	}
+	if s := e.Scaling; s != nil {
+		lines = append(lines, markdownScaling(e.Action, s)...)
+	}
+	if PodActions[e.Action] {
+		for _, pod := range e.Pods {
+			lines = append(lines, markdownPod(pod))
+		}
+	}
+	if n := e.Node; n != nil {
+		lines = append(lines, markdownNode(n)...)
+	}
+	for _, w := range e.Warnings {
+		lines = append(lines, fmt.Sprintf("- Warning **%s** x%d %s: %s", w.Reason, w.Count, w.Object, w.Message))
+	}
+	if len(e.Consumers) > 0 {
+		lines = append(lines, fmt.Sprintf("Consumers **%s**", strings.Join(e.Consumers, ", ")))
+	}
+	return lines
+}
+
+func markdownJob(e Event, j *Job) []string {
+	lines := []string{}
+	if j.Schedule != "" {
+		line := fmt.Sprintf("Schedule `%s`", j.Schedule)
+		if j.Suspend {
+			line += " **Suspended**"
+		}
+		if !j.LastSchedule.IsZero() {
+			line += fmt.Sprintf(" Last schedule at %s", j.LastSchedule.Format(time.RFC3339))
+		}
+		lines = append(lines, line)
+	}
+	if j.CronJob != "" {
+		lines = append(lines, fmt.Sprintf("CronJob **%s**", j.CronJob))
+	}
+	switch e.Action {
+	case JobStarted:
+		lines = append(lines, fmt.Sprintf("Completions **%d/%d**", j.Succeeded, j.Completions))
+	case JobSucceeded:
+		lines = append(lines, fmt.Sprintf(
+			"Duration **%s** Completions **%d/%d**", util.PrettyDuration(j.Duration, 2), j.Succeeded, j.Completions))
+	case JobFailed:
+		lines = append(lines,
+			fmt.Sprintf("Duration **%s** Failed **%d/%d**", util.PrettyDuration(j.Duration, 2), j.Failed, j.BackoffLimit),
+			fmt.Sprintf("Reason **%s**: %s", j.Reason, j.Message))
+		for _, pod := range e.Pods {
+			lines = append(lines, markdownLog(fmt.Sprintf("- **%s**: %s", pod.Name, pod), pod.Log))
+		}
+	}
+	return lines
+}
+
+func markdownScaling(action Action, s *Scaling) []string {
+	line := fmt.Sprintf("Replicas **%d** → **%d**", s.From, s.To)
+	if s.By != "" {
+		line += fmt.Sprintf(" by **%s**", s.By)
+	}
+	lines := []string{line}
+	if s.Max > 0 {
+		lines = append(lines, fmt.Sprintf("Range **%d-%d**", s.Min, s.Max))
+	}
+	for _, metric := range s.Metrics {
+		lines = append(lines, fmt.Sprintf("- `%s`", metric))
+	}
+	if s.Reason != "" && action != Scaled {
+		lines = append(lines, fmt.Sprintf("Reason **%s**: %s", s.Reason, s.Message))
+	}
+	return lines
+}
+
+func markdownNode(n *Node) []string {
+	lines := []string{}
+	if n.Reason != "" {
+		lines = append(lines, fmt.Sprintf("Reason **%s**: %s", n.Reason, n.Message))
+	}
+	if len(n.Conditions) > 0 {
+		lines = append(lines, fmt.Sprintf("Conditions **%s**", strings.Join(n.Conditions, ", ")))
+	}
+	if n.Unschedulable {
+		lines = append(lines, "**Unschedulable**")
+	}
+	for _, taint := range n.Added {
+		lines = append(lines, fmt.Sprintf("- Taint added `%s`", taint))
+	}
+	for _, taint := range n.Removed {
+		lines = append(lines, fmt.Sprintf("- Taint removed `%s`", taint))
+	}
+	if len(n.Workloads) > 0 {
+		lines = append(lines, fmt.Sprintf("Workloads **%s**", strings.Join(n.Workloads, ", ")))
+	}
+	return lines
+}
+
+func markdownPod(pod PodStatus) string {
+	line := fmt.Sprintf("- **%s**: %s", pod.Name, pod)
+	if pod.Restarts > 0 {
+		line += fmt.Sprintf(" restarts **%d**", pod.Restarts)
+	}
+	if pod.Message != "" {
+		line += fmt.Sprintf("\n  %s", pod.Message)
+	}
+	return markdownLog(line, pod.Log)
+}
+
+// markdownLog append log as code block to line.
+func markdownLog(line string, log string) string {
+	if log == "" {
+		return line
+	}
+	return line + "\n```\n" + strings.TrimRight(log, "\n") + "\n```"
+}
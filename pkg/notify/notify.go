@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var ErrClosed = fmt.Errorf("notifier closed")
+
+type Notifier interface {
+	Notify(Event) error
+}
+
+type NotifyFunc func(Event) error
+
+func (f NotifyFunc) Notify(e Event) error {
+	return f(e)
+}
+
+// Multi notify all notifiers concurrently, error is logged and ignored.
+func Multi(notifiers ...Notifier) Notifier {
+	return NotifyFunc(func(e Event) error {
+		group := wait.Group{}
+		for _, notifier := range notifiers {
+			n := notifier
+			group.Start(func() {
+				if err := n.Notify(e); err != nil {
+					log.Warn().Err(err).Msgf("ignore notify %s(%s)", e.Kind, e.Key())
+				}
+			})
+		}
+		group.Wait()
+		return nil
+	})
+}
+
+// Swappable forward event to the current notifier,
+// Swap wait in-flight events, so that the previous notifier can be closed safely.
+type Swappable struct {
+	mu       sync.RWMutex
+	notifier Notifier
+}
+
+func NewSwappable(notifier Notifier) *Swappable {
+	return &Swappable{notifier: notifier}
+}
+
+func (s *Swappable) Notify(e Event) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.notifier.Notify(e)
+}
+
+// Swap replace the current notifier, return the previous one after in-flight events are sent.
+func (s *Swappable) Swap(notifier Notifier) Notifier {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.notifier
+	s.notifier = notifier
+	return previous
+}
+
+func StdoutNotify(format Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		fmt.Println(format(e))
+		return nil
+	})
+}
@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwappable(t *testing.T) {
+	sent := make(chan string, 2)
+	started, release := make(chan struct{}), make(chan struct{})
+	previous := NotifyFunc(func(e Event) error {
+		close(started)
+		<-release
+		sent <- "previous"
+		return nil
+	})
+	s := NewSwappable(previous)
+
+	go func() { _ = s.Notify(Event{}) }()
+	<-started
+
+	swapped := make(chan Notifier)
+	go func() {
+		swapped <- s.Swap(NotifyFunc(func(e Event) error {
+			sent <- "current"
+			return nil
+		}))
+	}()
+
+	// NOTE: swap wait in-flight event
+	select {
+	case <-swapped:
+		t.Fatal("swapped before in-flight event is sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-swapped
+	require.Equal(t, "previous", <-sent)
+
+	require.NoError(t, s.Notify(Event{}))
+	require.Equal(t, "current", <-sent)
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"regexp"
+)
+
+// Match select event, empty field match all.
+type Match struct {
+	Namespaces map[string]bool
+	Kinds      map[string]bool
+	Actions    map[Action]bool
+	Name       *regexp.Regexp
+	Labels     map[string]string
+}
+
+func (m Match) Match(e Event) bool {
+	if len(m.Namespaces) > 0 && !m.Namespaces[e.Namespace] {
+		return false
+	}
+	if len(m.Kinds) > 0 && !m.Kinds[e.Kind] {
+		return false
+	}
+	if len(m.Actions) > 0 && !m.Actions[e.Action] {
+		return false
+	}
+	if m.Name != nil && !m.Name.MatchString(e.Name) {
+		return false
+	}
+	for k, v := range m.Labels {
+		if lv, ok := e.Labels[k]; !ok || lv != v {
+			return false
+		}
+	}
+	return true
+}
+
+type Route struct {
+	Match    Match
+	Notifier Notifier
+	// Continue to match following routes after matched
+	Continue bool
+}
+
+// Router send event to the first matched route,
+// or all matched routes until the one without Continue.
+// Fallback is used if no route matched, and can be nil.
+func Router(routes []Route, fallback Notifier) Notifier {
+	return NotifyFunc(func(e Event) error {
+		notifiers := []Notifier{}
+		for _, route := range routes {
+			if !route.Match.Match(e) {
+				continue
+			}
+			notifiers = append(notifiers, route.Notifier)
+			if !route.Continue {
+				break
+			}
+		}
+
+		if len(notifiers) == 0 {
+			if fallback == nil {
+				return nil
+			}
+			return fallback.Notify(e)
+		}
+		if len(notifiers) == 1 {
+			return notifiers[0].Notify(e)
+		}
+		return Multi(notifiers...).Notify(e)
+	})
+}
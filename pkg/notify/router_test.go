@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	mu := sync.Mutex{}
+	received := map[string][]string{}
+	recorder := func(name string) Notifier {
+		return NotifyFunc(func(e Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = append(received[name], e.Key())
+			return nil
+		})
+	}
+
+	router := Router([]Route{
+		{
+			Match:    Match{Kinds: map[string]bool{"Deployment": true}, Name: regexp.MustCompile(`^payments-`)},
+			Notifier: recorder("payments"),
+		},
+		{
+			Match:    Match{Namespaces: map[string]bool{"infra": true}, Labels: map[string]string{"team": "infra"}},
+			Notifier: recorder("infra"),
+			Continue: true,
+		},
+		{
+			Match:    Match{Actions: map[Action]bool{Deleted: true}},
+			Notifier: recorder("deleted"),
+		},
+	}, recorder("fallback"))
+
+	events := []Event{
+		{Kind: "Deployment", Namespace: "default", Name: "payments-api", Action: Updated},
+		{Kind: "DaemonSet", Namespace: "infra", Name: "agent", Labels: map[string]string{"team": "infra"}, Action: Deleted},
+		{Kind: "DaemonSet", Namespace: "infra", Name: "other", Action: Updated},
+	}
+	for _, e := range events {
+		require.NoError(t, router.Notify(e))
+	}
+
+	require.Equal(t, map[string][]string{
+		"payments": {"default/payments-api"},
+		"infra":    {"infra/agent"},
+		"deleted":  {"infra/agent"},
+		"fallback": {"infra/other"},
+	}, received)
+}
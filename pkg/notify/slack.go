@@ -0,0 +1,403 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/util"
+)
+
+// https://api.slack.com/reference/block-kit/blocks
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Fields   []*slackText `json:"fields,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Blocks      []slackBlock      `json:"blocks"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+const (
+	// slack allow at most 10 fields in section
+	slackMaxFields = 10
+	// slack allow at most 150 characters in header
+	slackMaxHeader = 150
+	// slack allow at most 2000 characters in field
+	slackMaxField = 2000
+	// slack allow at most 3000 characters in text of section
+	slackMaxText = 3000
+)
+
+var slackColors = map[Action]string{
+	Created:  "#2eb886",
+	Updated:  "#439fe0",
+	Deleted:  "#daa038",
+	NotReady: "#a30200",
+
+	RolloutStarted:     "#439fe0",
+	RolloutProgressing: "#439fe0",
+	RolloutCompleted:   "#2eb886",
+	RolloutFailed:      "#a30200",
+
+	RolledBack: "#daa038",
+
+	JobStarted:   "#439fe0",
+	JobSucceeded: "#2eb886",
+	JobFailed:    "#a30200",
+
+	Scaled:              "#439fe0",
+	AutoscalerPinned:    "#daa038",
+	AutoscalerInactive:  "#a30200",
+	AutoscalerRecovered: "#2eb886",
+
+	PodCrashLooping:     "#a30200",
+	PodOOMKilled:        "#a30200",
+	PodRestarting:       "#daa038",
+	PodEvicted:          "#daa038",
+	PodImagePullBackOff: "#a30200",
+
+	NodeAdded:            "#2eb886",
+	NodeRemoved:          "#daa038",
+	NodeReady:            "#2eb886",
+	NodeNotReady:         "#a30200",
+	NodePressure:         "#a30200",
+	NodePressureRelieved: "#2eb886",
+	NodeCordoned:         "#daa038",
+	NodeUncordoned:       "#2eb886",
+	NodeTainted:          "#daa038",
+}
+
+// SlackNotify post event as block kit message to slack incoming webhook,
+// format render the fallback text, body render the only section if not nil.
+func SlackNotify(addr string, timeFormat string, style DiffStyle, format Formatter, body Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		return postJSON(addr, slackRender(e, timeFormat, style, format, body), nil)
+	})
+}
+
+func SlacksNotify(addrs []string, timeFormat string, style DiffStyle, format Formatter, body Formatter) Notifier {
+	hooks := make([]Notifier, len(addrs))
+	for i, addr := range addrs {
+		hooks[i] = SlackNotify(addr, timeFormat, style, format, body)
+	}
+	return Multi(hooks...)
+}
+
+func slackRender(e Event, timeFormat string, style DiffStyle, format Formatter, body Formatter) slackMessage {
+	header := slackBlock{
+		Type: "header",
+		Text: &slackText{
+			Type: "plain_text",
+			Text: truncate(fmt.Sprintf("%s %s %s", e.Kind, e.Key(), e.Action), slackMaxHeader),
+		},
+	}
+
+	contexts := []*slackText{
+		{Type: "mrkdwn", Text: fmt.Sprintf("*%s* at %s", e.Action, e.Timestamp.Format(timeFormat))},
+	}
+	if len(e.Managers) > 0 {
+		contexts[0].Text += fmt.Sprintf(" by *%s*", strings.Join(e.Managers, ", "))
+	}
+	fields := []*slackText{}
+	code := ""
+	if r := e.Rollback; r != nil {
+		contexts = append(contexts, &slackText{
+			Type: "mrkdwn",
+			Text: truncate(fmt.Sprintf(
+				"Revision *%s* → *%s*\nImages `%s` → `%s`",
+				r.FromRevision, r.ToRevision,
+				strings.Join(r.FromImages, ", "), strings.Join(r.ToImages, ", ")), slackMaxField),
+		})
+	}
+
+	switch e.Action {
+	case Updated, RolledBack:
+		paths := map[string]bool{}
+		for _, image := range e.Images {
+			paths[image.Path] = true
+			text := fmt.Sprintf("*Image*\n%s", image.Summary)
+			if image.Link != "" {
+				text = fmt.Sprintf("*Image*\n<%s|%s>", image.Link, image.Summary)
+			}
+			fields = append(fields, &slackText{Type: "mrkdwn", Text: truncate(text, slackMaxField)})
+		}
+		if style == DiffUnified {
+			if diff := unifiedDiff(unifiedChanges(e)); diff != "" {
+				code = "```" + truncate(diff, slackMaxText-6) + "```"
+			}
+			break
+		}
+		for _, change := range e.Changes {
+			if paths[change.Path] {
+				continue
+			}
+			text := fmt.Sprintf("*%s*\n`%v` → `%v`", change.Path, change.From, change.To)
+			if change.Delta != "" {
+				text = fmt.Sprintf("%s (%s)", text, change.Delta)
+			}
+			fields = append(fields, &slackText{Type: "mrkdwn", Text: truncate(text, slackMaxField)})
+		}
+	case NotReady:
+		contexts = append(contexts, &slackText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("Age *%s* Ready *%d/%d*", util.PrettyDuration(e.Age, 2), e.Ready, e.Desired),
+		})
+		for _, pod := range e.Pods {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*%s*\n%s", pod.Name, pod), slackMaxField),
+			})
+		}
+	}
+
+	if r := e.Rollout; r != nil {
+		contexts = append(contexts, &slackText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf(
+				"Generation *%d* Updated *%d/%d* (%d%%) Ready *%d/%d*",
+				r.Generation, r.Updated, r.Desired, r.Progress(), r.Ready, r.Desired),
+		})
+		if r.Partition > 0 {
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("Partition *%d*, %d of %d pods on new revision", r.Partition, r.Updated, r.Desired),
+			})
+		}
+		if r.Duration > 0 {
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("Duration *%s*", util.PrettyDuration(r.Duration, 2)),
+			})
+		}
+		if len(r.Blocking) > 0 {
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("Blocking *%s*", strings.Join(r.Blocking, ", ")), slackMaxField),
+			})
+		}
+		if r.Reason != "" {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*%s*\n%s", r.Reason, r.Message), slackMaxField),
+			})
+		}
+		if e.Action == RolloutFailed {
+			for _, pod := range e.Pods {
+				fields = append(fields, &slackText{
+					Type: "mrkdwn",
+					Text: truncate(fmt.Sprintf("*%s*\n%s", pod.Name, pod), slackMaxField),
+				})
+			}
+		}
+	}
+
+	if j := e.Job; j != nil {
+		if j.Schedule != "" {
+			text := fmt.Sprintf("Schedule `%s`", j.Schedule)
+			if j.Suspend {
+				text += " *Suspended*"
+			}
+			if !j.LastSchedule.IsZero() {
+				text += fmt.Sprintf(" Last schedule at %s", j.LastSchedule.Format(time.RFC3339))
+			}
+			contexts = append(contexts, &slackText{Type: "mrkdwn", Text: text})
+		}
+		if j.CronJob != "" {
+			contexts = append(contexts, &slackText{Type: "mrkdwn", Text: fmt.Sprintf("CronJob *%s*", j.CronJob)})
+		}
+		switch e.Action {
+		case JobStarted:
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("Completions *%d/%d*", j.Succeeded, j.Completions),
+			})
+		case JobSucceeded:
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("Duration *%s* Completions *%d/%d*", util.PrettyDuration(j.Duration, 2), j.Succeeded, j.Completions),
+			})
+		case JobFailed:
+			contexts = append(contexts, &slackText{
+				Type: "mrkdwn",
+				Text: fmt.Sprintf("Duration *%s* Failed *%d/%d*", util.PrettyDuration(j.Duration, 2), j.Failed, j.BackoffLimit),
+			})
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*%s*\n%s", j.Reason, j.Message), slackMaxField),
+			})
+			for _, pod := range e.Pods {
+				fields = append(fields, &slackText{
+					Type: "mrkdwn",
+					Text: truncate(fmt.Sprintf("*%s*\n%s", pod.Name, pod), slackMaxField),
+				})
+			}
+		}
+	}
+
+	if s := e.Scaling; s != nil {
+		text := fmt.Sprintf("Replicas *%d* → *%d*", s.From, s.To)
+		if s.By != "" {
+			text += fmt.Sprintf(" by *%s*", s.By)
+		}
+		if s.Max > 0 {
+			text += fmt.Sprintf(" Range *%d-%d*", s.Min, s.Max)
+		}
+		contexts = append(contexts, &slackText{Type: "mrkdwn", Text: text})
+		if len(s.Metrics) > 0 {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*Metrics*\n%s", strings.Join(s.Metrics, "\n")), slackMaxField),
+			})
+		}
+		if s.Reason != "" && e.Action != Scaled {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*%s*\n%s", s.Reason, s.Message), slackMaxField),
+			})
+		}
+	}
+	if PodActions[e.Action] {
+		for _, pod := range e.Pods {
+			text := fmt.Sprintf("*%s*\n%s", pod.Name, pod)
+			if pod.Restarts > 0 {
+				text += fmt.Sprintf(" restarts *%d*", pod.Restarts)
+			}
+			if pod.Message != "" {
+				text += "\n" + pod.Message
+			}
+			fields = append(fields, &slackText{Type: "mrkdwn", Text: truncate(text, slackMaxField)})
+		}
+	}
+	if n := e.Node; n != nil {
+		if len(n.Conditions) > 0 || n.Unschedulable {
+			text := fmt.Sprintf("Conditions *%s*", strings.Join(n.Conditions, ", "))
+			if n.Unschedulable {
+				text += " *Unschedulable*"
+			}
+			contexts = append(contexts, &slackText{Type: "mrkdwn", Text: text})
+		}
+		if n.Reason != "" {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*%s*\n%s", n.Reason, n.Message), slackMaxField),
+			})
+		}
+		if len(n.Added) > 0 {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*Taints added*\n%s", strings.Join(n.Added, "\n")), slackMaxField),
+			})
+		}
+		if len(n.Removed) > 0 {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*Taints removed*\n%s", strings.Join(n.Removed, "\n")), slackMaxField),
+			})
+		}
+		if len(n.Workloads) > 0 {
+			fields = append(fields, &slackText{
+				Type: "mrkdwn",
+				Text: truncate(fmt.Sprintf("*Workloads*\n%s", strings.Join(n.Workloads, "\n")), slackMaxField),
+			})
+		}
+	}
+	for _, w := range e.Warnings {
+		fields = append(fields, &slackText{
+			Type: "mrkdwn",
+			Text: truncate(fmt.Sprintf("*%s* x%d %s\n%s", w.Reason, w.Count, w.Object, w.Message), slackMaxField),
+		})
+	}
+	if len(e.Consumers) > 0 {
+		contexts = append(contexts, &slackText{
+			Type: "mrkdwn",
+			Text: truncate(fmt.Sprintf("Consumers *%s*", strings.Join(e.Consumers, ", ")), slackMaxField),
+		})
+	}
+
+	blocks := []slackBlock{{Type: "context", Elements: contexts}}
+	for len(fields) > 0 {
+		n := slackMaxFields
+		if len(fields) < n {
+			n = len(fields)
+		}
+		blocks = append(blocks, slackBlock{Type: "section", Fields: fields[:n]})
+		fields = fields[n:]
+	}
+	if code != "" {
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: code}})
+	}
+	for _, pod := range e.Pods {
+		if pod.Log == "" {
+			continue
+		}
+		// NOTE: keep the tail since the latest lines explain the failure
+		header := fmt.Sprintf("*%s*\n", pod.Name)
+		blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{
+			Type: "mrkdwn",
+			Text: header + "```" + truncateHead(strings.TrimRight(pod.Log, "\n"), slackMaxText-len([]rune(header))-6) + "```",
+		}})
+	}
+
+	color, ok := slackColors[e.Action]
+	if !ok {
+		color = "#808080"
+	}
+
+	if body != nil {
+		if text := body(e); text != "" {
+			blocks = []slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: truncate(text, slackMaxText)}}}
+		}
+	}
+
+	return slackMessage{
+		Text:        format(e),
+		Blocks:      []slackBlock{header},
+		Attachments: []slackAttachment{{Color: color, Blocks: blocks}},
+	}
+}
+
+// truncateHead keep the last n characters of s.
+func truncateHead(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		// NOTE: no room for ellipsis, n is set by template
+		return string(r[len(r)-clamp(n):])
+	}
+	return "..." + string(r[len(r)-n+3:])
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:clamp(n)])
+	}
+	return string(r[:n-3]) + "..."
+}
+
+func clamp(n int) int {
+	if n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackRender(t *testing.T) {
+	e := Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated}
+	for i := 0; i < 12; i++ {
+		e.Changes = append(e.Changes, Change{Path: fmt.Sprintf("metadata.labels.l%d", i), From: nil, To: "v"})
+	}
+
+	msg := slackRender(e, DefaultTimeFormat, DiffInline, TextFormatter(DefaultTimeFormat, false), nil)
+	require.Equal(t, "Deployment default/app Updated", msg.Blocks[0].Text.Text)
+	require.Len(t, msg.Attachments, 1)
+	require.Equal(t, slackColors[Updated], msg.Attachments[0].Color)
+
+	// context + 2 sections
+	blocks := msg.Attachments[0].Blocks
+	require.Len(t, blocks, 3)
+	require.Len(t, blocks[1].Fields, 10)
+	require.Len(t, blocks[2].Fields, 2)
+	require.Equal(t, "*metadata.labels.l0*\n`<nil>` → `v`", blocks[1].Fields[0].Text)
+}
+
+func TestSlackRenderBody(t *testing.T) {
+	e := Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated}
+	body := func(e Event) string { return e.Name + " is updated" }
+
+	msg := slackRender(e, DefaultTimeFormat, DiffInline, TextFormatter(DefaultTimeFormat, false), body)
+	require.Len(t, msg.Attachments[0].Blocks, 1)
+	require.Equal(t, "app is updated", msg.Attachments[0].Blocks[0].Text.Text)
+}
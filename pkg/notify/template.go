@@ -0,0 +1,76 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+// TemplateFuncs is the helper functions available in template.
+var TemplateFuncs = template.FuncMap{
+	// duration: {{ .Age | duration }}
+	"duration": func(d time.Duration) string {
+		return util.PrettyDuration(d, 2)
+	},
+	// time: {{ .Timestamp | time "15:04:05" }}
+	"time": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+	// truncate: {{ .Name | truncate 20 }}
+	"truncate": func(n int, s string) string {
+		return truncate(s, n)
+	},
+	// join: {{ .Names | join ", " }}
+	"join": func(sep string, elems []string) string {
+		return strings.Join(elems, sep)
+	},
+	// diff: {{ range .Changes }}{{ diff . }}{{ end }}
+	"diff": func(change Change) string {
+		return change.String()
+	},
+	// diffs: {{ .Changes | diffs "\n" }}
+	"diffs": func(sep string, changes []Change) string {
+		s := make([]string, len(changes))
+		for i, change := range changes {
+			s[i] = change.String()
+		}
+		return strings.Join(s, sep)
+	},
+	// unified: {{ .Changes | unified }}
+	"unified": func(changes []Change) string {
+		return unifiedDiff(changes)
+	},
+}
+
+// TemplateFormatter render event by text/template.
+// The template named as action, e.g. {{ define "Updated" }},
+// is used if defined, otherwise the root template.
+// Fallback is used if neither is defined or render failed.
+func TemplateFormatter(text string, fallback Formatter) (Formatter, error) {
+	tmpl, err := template.New("kubenotify").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return func(e Event) string {
+		t := tmpl.Lookup(string(e.Action))
+		if t == nil {
+			t = tmpl
+		}
+		if t.Tree == nil || t.Tree.Root == nil || len(t.Tree.Root.Nodes) == 0 {
+			return fallback(e)
+		}
+
+		buf := bytes.Buffer{}
+		if err := t.Execute(&buf, e); err != nil {
+			log.Warn().Err(err).Msgf("render %s(%s) with template %s", e.Kind, e.Key(), t.Name())
+			return fallback(e)
+		}
+		return strings.TrimSpace(buf.String())
+	}, nil
+}
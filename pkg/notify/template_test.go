@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	text := `
+{{- define "Updated" }}{{ .Kind }} {{ .Name | truncate 5 }} changed: {{ .Changes | diffs ", " }}{{ end -}}
+{{- define "NotReady" }}{{ .Name }} not ready after {{ .Age | duration }}{{ end -}}
+{{ .Action }} {{ .Key }} at {{ .Timestamp | time "15:04" }}`
+
+	format, err := TemplateFormatter(text, TextFormatter(DefaultTimeFormat, false))
+	require.NoError(t, err)
+
+	ts := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		name     string
+		e        Event
+		expected string
+	}{
+		{
+			"root",
+			Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Created, Timestamp: ts},
+			"Created default/app at 08:00",
+		},
+		{
+			"updated",
+			Event{
+				Kind: "Deployment", Namespace: "default", Name: "application", Action: Updated,
+				Changes: []Change{{Path: "spec.replicas", From: 1, To: 2}, {Path: "metadata.labels.a", To: "b"}},
+			},
+			"Deployment ap... changed: spec.replicas(1 - 2), metadata.labels.a(<nil> - b)",
+		},
+		{
+			"notready",
+			Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: NotReady, Age: 61 * time.Second},
+			"app not ready after 1m1s",
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			require.Equal(t, fixture.expected, format(fixture.e))
+		})
+	}
+}
+
+func TestTemplateFormatterFallback(t *testing.T) {
+	format, err := TemplateFormatter(`{{ define "Deleted" }}{{ .Name }} gone{{ end }}`, TextFormatter(DefaultTimeFormat, false))
+	require.NoError(t, err)
+
+	ts := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, "app gone", format(Event{Name: "app", Action: Deleted}))
+	require.Equal(
+		t, "Deployment(default/app) CreatedAt(08:00:00Z)",
+		format(Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: Created, Timestamp: ts}))
+}
+
+func TestTemplateTruncateShort(t *testing.T) {
+	format, err := TemplateFormatter(`{{ .Name | truncate 2 }}|{{ .Name | truncate 0 }}|{{ .Name | truncate 4 }}`, TextFormatter(DefaultTimeFormat, false))
+	require.NoError(t, err)
+	require.Equal(t, "ap||a...", format(Event{Name: "application"}))
+
+	require.Equal(t, "on", truncateHead("application", 2))
+	require.Equal(t, "", truncateHead("application", -1))
+}
@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DiffStyle is how changes are rendered.
+type DiffStyle string
+
+const (
+	// DiffInline render each change in one line, e.g. path(from - to)
+	DiffInline DiffStyle = "inline"
+	// DiffUnified render each change as unified diff of yaml, e.g.
+	//
+	//	@@ spec.template.spec.containers[app].args @@
+	//	  - --verbose
+	//	- - --port=80
+	//	+ - --port=8080
+	DiffUnified DiffStyle = "unified"
+)
+
+// ParseDiffStyle return DiffInline if s is empty.
+func ParseDiffStyle(s string) (DiffStyle, error) {
+	switch DiffStyle(s) {
+	case "", DiffInline:
+		return DiffInline, nil
+	case DiffUnified:
+		return DiffUnified, nil
+	}
+	return "", fmt.Errorf("unknown diff style: %s", s)
+}
+
+// maxDiffLines limit lines of yaml to compare, it is O(n*m).
+const maxDiffLines = 500
+
+// diffContext is the number of common lines kept around changed lines.
+const diffContext = 3
+
+// unifiedDiff render changes as unified diff.
+func unifiedDiff(changes []Change) string {
+	lines := []string{}
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("@@ %s @@", change.Path))
+		lines = append(lines, trimContext(diffLines(yamlLines(change.From), yamlLines(change.To)), diffContext)...)
+		if change.Delta != "" {
+			lines = append(lines, "# "+change.Delta)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// yamlLines render v as yaml, multi-line string is split as is.
+func yamlLines(v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+	if s, ok := v.(string); ok && strings.Contains(s, "\n") {
+		return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return []string{fmt.Sprintf("%v", v)}
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// diffLines compare lines by longest common subsequence,
+// prefix common line with space, removed with -, added with +.
+func diffLines(from, to []string) []string {
+	if len(from) > maxDiffLines || len(to) > maxDiffLines {
+		lines := make([]string, 0, len(from)+len(to))
+		for _, line := range from {
+			lines = append(lines, "- "+line)
+		}
+		for _, line := range to {
+			lines = append(lines, "+ "+line)
+		}
+		return lines
+	}
+
+	// lcs[i][j] is length of lcs of from[i:] and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, "  "+from[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, "- "+from[i])
+			i++
+		default:
+			lines = append(lines, "+ "+to[j])
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		lines = append(lines, "- "+from[i])
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, "+ "+to[j])
+	}
+	return lines
+}
+
+// trimContext keep at most n common lines around changed lines, replace the others with "  ...".
+func trimContext(lines []string, n int) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		for j := i - n; j <= i+n; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+
+	trimmed := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if keep[i] {
+			trimmed = append(trimmed, line)
+		} else if i == 0 || keep[i-1] {
+			trimmed = append(trimmed, "  ...")
+		}
+	}
+	return trimmed
+}
+
+// UnifiedFormatter render event as TextFormatter,
+// followed by unified diff of changes in following lines.
+func UnifiedFormatter(timeFormat string, verbose bool) Formatter {
+	text := TextFormatter(timeFormat, verbose)
+	return func(e Event) string {
+		changes := unifiedChanges(e)
+		e.Changes = nil
+		msg := text(e)
+		if diff := unifiedDiff(changes); diff != "" {
+			msg = msg + "\n" + diff
+		}
+		return msg
+	}
+}
+
+// unifiedChanges return Hunks if any, otherwise changes except images.
+func unifiedChanges(e Event) []Change {
+	if len(e.Hunks) > 0 {
+		return e.Hunks
+	}
+	return skipImages(e.Changes, e.Images)
+}
+
+// skipImages remove change of image, which is rendered as summary.
+func skipImages(changes []Change, images []ImageChange) []Change {
+	if len(images) == 0 {
+		return changes
+	}
+	paths := map[string]bool{}
+	for _, image := range images {
+		paths[image.Path] = true
+	}
+	filtered := make([]Change, 0, len(changes))
+	for _, change := range changes {
+		if !paths[change.Path] {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
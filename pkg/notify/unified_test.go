@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	fixtures := []struct {
+		name     string
+		change   Change
+		expected string
+	}{
+		{
+			"scalar",
+			Change{Path: "spec.replicas", From: 1, To: 2},
+			"@@ spec.replicas @@\n- 1\n+ 2",
+		},
+		{
+			"map",
+			Change{
+				Path: "spec.template.spec.containers[app].resources",
+				From: map[string]interface{}{"limits": map[string]interface{}{"cpu": "1", "memory": "1Gi"}},
+				To:   map[string]interface{}{"limits": map[string]interface{}{"cpu": "1", "memory": "2Gi"}},
+			},
+			"@@ spec.template.spec.containers[app].resources @@\n" +
+				"  limits:\n" +
+				"    cpu: \"1\"\n" +
+				"-   memory: 1Gi\n" +
+				"+   memory: 2Gi",
+		},
+		{
+			"multi-line",
+			Change{Path: "data.config", From: "a: 1\nb: 2\n", To: "a: 1\nb: 3\nc: 4\n"},
+			"@@ data.config @@\n  a: 1\n- b: 2\n+ b: 3\n+ c: 4",
+		},
+		{
+			"added with delta",
+			Change{Path: "spec.template.spec.containers[app].resources.limits.memory", From: nil, To: "1Gi", Delta: "memory limit +1Gi"},
+			"@@ spec.template.spec.containers[app].resources.limits.memory @@\n+ 1Gi\n# memory limit +1Gi",
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			require.Equal(t, fixture.expected, unifiedDiff([]Change{fixture.change}))
+		})
+	}
+}
+
+func TestUnifiedFormatter(t *testing.T) {
+	e := Event{
+		Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated,
+		Timestamp: time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC),
+		Changes: []Change{
+			{Path: "spec.template.spec.containers[app].image", From: "app:v1", To: "app:v2"},
+			{Path: "spec.template.spec.containers[app].args.1", From: "--port=80", To: "--port=8080"},
+		},
+		Images: []ImageChange{{Path: "spec.template.spec.containers[app].image", Summary: "app v1 → v2"}},
+	}
+	require.Equal(t,
+		"Deployment(default/app) ChangedAt(08:00:00Z) Image(app v1 → v2)\n"+
+			"@@ spec.template.spec.containers[app].args.1 @@\n- --port=80\n+ --port=8080",
+		UnifiedFormatter(DefaultTimeFormat, false)(e))
+}
+
+func TestUnifiedFormatterHunks(t *testing.T) {
+	args := func(port string) []interface{} {
+		return []interface{}{"--host=0.0.0.0", "--log=info", "--metrics", "--pprof", "--port=" + port, "--debug"}
+	}
+	e := Event{
+		Kind: "Deployment", Namespace: "default", Name: "app", Action: Updated,
+		Timestamp: time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC),
+		Changes:   []Change{{Path: "spec.template.spec.containers[app].args.4", From: "--port=80", To: "--port=8080"}},
+		Hunks:     []Change{{Path: "spec.template.spec.containers[app].args", From: args("80"), To: args("8080")}},
+	}
+	require.Equal(t,
+		"Deployment(default/app) ChangedAt(08:00:00Z)\n"+
+			"@@ spec.template.spec.containers[app].args @@\n"+
+			"  ...\n"+
+			"  - --log=info\n"+
+			"  - --metrics\n"+
+			"  - --pprof\n"+
+			"- - --port=80\n"+
+			"+ - --port=8080\n"+
+			"  - --debug",
+		UnifiedFormatter(DefaultTimeFormat, false)(e))
+}
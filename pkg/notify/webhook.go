@@ -4,69 +4,80 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"github.com/rs/zerolog/log"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 )
 
-type NotifyFunc func(string) error
-
-func WebhookNotify(addr string) NotifyFunc {
-	return func(msg string) error {
-		return retry.OnError(
-			retry.DefaultBackoff,
-			func(err error) bool {
-				log.Warn().Err(err).Send()
-				return true
-			},
-			func() error {
-				body, err := json.Marshal(map[string]string{"message": msg})
-				if err != nil {
-					return fmt.Errorf("json marshal: %w", err)
-				}
-
-				resp, err := http.Post(addr, "application/json", bytes.NewBuffer(body))
-				if err != nil {
-					return fmt.Errorf("post %s with error: %w", addr, err)
-				}
-				defer resp.Body.Close()
-
-				if resp.StatusCode != http.StatusOK {
-					return fmt.Errorf("post %s without ok: %d", addr, resp.StatusCode)
-				}
+// WebhookNotify post {"message": ..., "event": ...} to addr.
+func WebhookNotify(addr string, format Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		return postJSON(addr, map[string]interface{}{
+			"message": format(e),
+			"event":   e,
+		}, nil)
+	})
+}
 
-				return nil
-			},
-		)
+func WebhooksNotify(addrs []string, format Formatter) Notifier {
+	hooks := make([]Notifier, len(addrs))
+	for i, addr := range addrs {
+		hooks[i] = WebhookNotify(addr, format)
 	}
+	return Multi(hooks...)
 }
 
-func WebhooksNotify(addrs []string) NotifyFunc {
-	hooks := make([]NotifyFunc, len(addrs))
-	for i, addr := range addrs {
-		hooks[i] = WebhookNotify(addr)
+// postJSON post payload to addr with retry,
+// check is used to verify response body if not nil.
+func postJSON(addr string, payload interface{}, check func([]byte) error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
 	}
 
-	return func(msg string) error {
-		group := wait.Group{}
-		for _, hook := range hooks {
-			hookFunc := hook
-			group.Start(func() {
-				if err := hookFunc(msg); err != nil {
-					log.Warn().Err(err).Msgf("ignore notify %s", msg)
+	return retry.OnError(
+		retry.DefaultBackoff,
+		func(err error) bool {
+			log.Warn().Err(err).Send()
+			return true
+		},
+		func() error {
+			resp, err := http.Post(addr, "application/json", bytes.NewBuffer(body))
+			if err != nil {
+				return fmt.Errorf("post %s with error: %w", addr, err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("post %s without ok: %d", addr, resp.StatusCode)
+			}
+
+			if check != nil {
+				b, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					return fmt.Errorf("read response of %s: %w", addr, err)
 				}
-			})
-		}
-		group.Wait()
-		return nil
-	}
+				if err := check(b); err != nil {
+					return fmt.Errorf("post %s: %w", addr, err)
+				}
+			}
+
+			return nil
+		},
+	)
 }
 
-func StdoutNotify() NotifyFunc {
-	return func(msg string) error {
-		fmt.Println(msg)
-		return nil
+// parseWebhook split secret from addr, the secret is set as fragment,
+// e.g. https://oapi.dingtalk.com/robot/send?access_token=xxx#SECxxx
+func parseWebhook(raw string) (addr string, secret string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("parse webhook: %w", err)
 	}
+	secret = u.Fragment
+	u.Fragment = ""
+	return u.String(), secret, nil
 }
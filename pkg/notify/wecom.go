@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// wecom allow at most 4096 bytes in content of markdown
+const wecomMaxContent = 4096
+
+// https://developer.work.weixin.qq.com/document/path/91770
+type wecomMarkdown struct {
+	Content string `json:"content"`
+}
+
+type wecomMessage struct {
+	MsgType  string        `json:"msgtype"`
+	Markdown wecomMarkdown `json:"markdown"`
+}
+
+var wecomColors = map[Action]string{
+	Created:  "info",
+	Updated:  "comment",
+	Deleted:  "warning",
+	NotReady: "warning",
+
+	RolloutStarted:     "comment",
+	RolloutProgressing: "comment",
+	RolloutCompleted:   "info",
+	RolloutFailed:      "warning",
+
+	RolledBack: "warning",
+
+	JobStarted:   "comment",
+	JobSucceeded: "info",
+	JobFailed:    "warning",
+
+	Scaled:              "comment",
+	AutoscalerPinned:    "warning",
+	AutoscalerInactive:  "warning",
+	AutoscalerRecovered: "info",
+
+	PodCrashLooping:     "warning",
+	PodOOMKilled:        "warning",
+	PodRestarting:       "warning",
+	PodEvicted:          "warning",
+	PodImagePullBackOff: "warning",
+
+	NodeAdded:            "info",
+	NodeRemoved:          "warning",
+	NodeReady:            "info",
+	NodeNotReady:         "warning",
+	NodePressure:         "warning",
+	NodePressureRelieved: "info",
+	NodeCordoned:         "warning",
+	NodeUncordoned:       "info",
+	NodeTainted:          "comment",
+}
+
+// WecomNotify post event as markdown to wecom group robot, body render content if not nil.
+// NOTE: wecom robot is authorized by key in addr, and does not support sign.
+func WecomNotify(addr string, timeFormat string, style DiffStyle, body Formatter) Notifier {
+	return NotifyFunc(func(e Event) error {
+		return postJSON(addr, wecomRender(e, timeFormat, style, body), errcodeCheck)
+	})
+}
+
+func WecomsNotify(addrs []string, timeFormat string, style DiffStyle, body Formatter) Notifier {
+	hooks := make([]Notifier, len(addrs))
+	for i, addr := range addrs {
+		hooks[i] = WecomNotify(addr, timeFormat, style, body)
+	}
+	return Multi(hooks...)
+}
+
+func wecomRender(e Event, timeFormat string, style DiffStyle, body Formatter) wecomMessage {
+	color, ok := wecomColors[e.Action]
+	if !ok {
+		color = "comment"
+	}
+	lines := append(
+		[]string{fmt.Sprintf(`### <font color="%s">%s</font>`, color, markdownTitle(e))},
+		markdownBody(e, timeFormat, style, body)...)
+	return wecomMessage{
+		MsgType:  "markdown",
+		Markdown: wecomMarkdown{Content: truncateBytes(strings.Join(lines, "\n"), wecomMaxContent)},
+	}
+}
+
+// truncateBytes is truncate by bytes of utf-8, without splitting rune.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	i := n - 3
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return s[:i] + "..."
+}
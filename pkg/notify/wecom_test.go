@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWecomRender(t *testing.T) {
+	e := Event{Kind: "Deployment", Namespace: "default", Name: "app", Action: RolloutFailed}
+	msg := wecomRender(e, DefaultTimeFormat, DiffInline, nil)
+	require.Equal(t, "markdown", msg.MsgType)
+	require.True(t, strings.HasPrefix(msg.Markdown.Content,
+		`### <font color="warning">Deployment default/app RolloutFailed</font>`))
+
+	body, err := TemplateFormatter(`{{ define "Updated" }}{{ .Name }} is updated{{ end }}`, func(Event) string { return "" })
+	require.NoError(t, err)
+	e.Action = Updated
+	msg = wecomRender(e, DefaultTimeFormat, DiffInline, body)
+	require.Equal(t, "### <font color=\"comment\">Deployment default/app Updated</font>\napp is updated", msg.Markdown.Content)
+
+	// NOTE: content is limited by bytes, not characters
+	e.Changes = []Change{{Path: "data.config", From: "", To: strings.Repeat("配置", 2000)}}
+	msg = wecomRender(e, DefaultTimeFormat, DiffInline, nil)
+	require.LessOrEqual(t, len(msg.Markdown.Content), wecomMaxContent)
+	require.True(t, utf8.ValidString(msg.Markdown.Content))
+	require.True(t, strings.HasSuffix(msg.Markdown.Content, "..."))
+}
+
+func TestTruncateBytes(t *testing.T) {
+	require.Equal(t, "abc", truncateBytes("abc", 3))
+	require.Equal(t, "ab...", truncateBytes("abcdef", 5))
+	require.Equal(t, "配...", truncateBytes("配置项", 7))
+}
@@ -0,0 +1,156 @@
+package sentry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/j2gg0s/kubenotify/pkg/diff"
+	"github.com/j2gg0s/kubenotify/pkg/util"
+
+	corev1 "k8s.io/api/core/v1"
+	metaapi "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ignoredSecretTypes are managed by kubernetes or tools and changed frequently.
+var ignoredSecretTypes = map[corev1.SecretType]bool{
+	corev1.SecretTypeServiceAccountToken: true,
+	"helm.sh/release.v1":                 true,
+}
+
+// leaderAnnotation is renewed every few seconds by leader election which use ConfigMap as lock.
+const leaderAnnotation = "control-plane.alpha.kubernetes.io/leader"
+
+// ignoredConfigMaps are status written by controllers and changed frequently, keyed by namespace/name.
+var ignoredConfigMaps = map[string]bool{
+	"kube-system/cluster-autoscaler-status": true,
+}
+
+// ignoredConfigMap return whether ConfigMap is a leader election lock or status of controller.
+func ignoredConfigMap(cm *corev1.ConfigMap) bool {
+	if _, ok := cm.Annotations[leaderAnnotation]; ok {
+		return true
+	}
+	return ignoredConfigMaps[cm.Namespace+"/"+cm.Name]
+}
+
+// secretData match data of Secret, and last applied annotation which contains data too.
+var secretData = regexp.MustCompile(`^(data|stringData)\.|^metadata\.annotations\.kubectl\.kubernetes\.io/last-applied-configuration$`)
+
+// secretRedactor always redact data of Secret, in addition to r.
+func secretRedactor(r *diff.Redactor) *diff.Redactor {
+	sr := diff.Redactor{Paths: []*regexp.Regexp{secretData}}
+	if r != nil {
+		sr.Paths = append(sr.Paths, r.Paths...)
+		sr.Flags = r.Flags
+		sr.Key = r.Key
+	}
+	return &sr
+}
+
+// consumers return workloads in namespace whose pod template reference the ConfigMap or Secret,
+// e.g. Deployment/app, by volumes, env and envFrom.
+func (ctl *Controller) consumers(kind, ns, name string) ([]string, error) {
+	workloads := []interface{}{}
+	ds, err := ctl.dLister.Deployments(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list deployments(%s): %w", ns, err)
+	}
+	for _, d := range ds {
+		workloads = append(workloads, d)
+	}
+	sss, err := ctl.ssLister.StatefulSets(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list statefulsets(%s): %w", ns, err)
+	}
+	for _, ss := range sss {
+		workloads = append(workloads, ss)
+	}
+	dss, err := ctl.dsLister.DaemonSets(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list daemonsets(%s): %w", ns, err)
+	}
+	for _, ds := range dss {
+		workloads = append(workloads, ds)
+	}
+	if ctl.cjLister != nil {
+		cjs, err := ctl.cjLister.CronJobs(ns).List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("list cronjobs(%s): %w", ns, err)
+		}
+		for _, cj := range cjs {
+			workloads = append(workloads, cj)
+		}
+	}
+
+	consumers := []string{}
+	for _, workload := range workloads {
+		spec, err := util.PodTemplateAccessor(workload)
+		if err != nil {
+			continue
+		}
+		if references(spec, kind, name) {
+			meta, err := metaapi.Accessor(workload)
+			if err != nil {
+				continue
+			}
+			consumers = append(consumers, fmt.Sprintf("%s/%s", util.KindAccessor(workload), meta.GetName()))
+		}
+	}
+	sort.Strings(consumers)
+	return consumers, nil
+}
+
+// references check whether pod reference ConfigMap or Secret named as name.
+func references(spec corev1.PodSpec, kind, name string) bool {
+	for _, volume := range spec.Volumes {
+		switch {
+		case kind == "ConfigMap" && volume.ConfigMap != nil && volume.ConfigMap.Name == name:
+			return true
+		case kind == "Secret" && volume.Secret != nil && volume.Secret.SecretName == name:
+			return true
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if kind == "ConfigMap" && source.ConfigMap != nil && source.ConfigMap.Name == name {
+					return true
+				}
+				if kind == "Secret" && source.Secret != nil && source.Secret.Name == name {
+					return true
+				}
+			}
+		}
+	}
+
+	if kind == "Secret" {
+		for _, secret := range spec.ImagePullSecrets {
+			if secret.Name == name {
+				return true
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, from := range container.EnvFrom {
+			if kind == "ConfigMap" && from.ConfigMapRef != nil && from.ConfigMapRef.Name == name {
+				return true
+			}
+			if kind == "Secret" && from.SecretRef != nil && from.SecretRef.Name == name {
+				return true
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if kind == "ConfigMap" && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name {
+				return true
+			}
+			if kind == "Secret" && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
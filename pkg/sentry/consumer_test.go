@@ -0,0 +1,86 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/j2gg0s/kubenotify/pkg/diff"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReferences(t *testing.T) {
+	spec := corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "config", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "app-config"}},
+			}},
+			{Name: "certs", VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{{
+					Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "app-tls"}},
+				}}},
+			}},
+		},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		InitContainers: []corev1.Container{{
+			Name:    "migrate",
+			EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "db"}}}},
+		}},
+		Containers: []corev1.Container{{
+			Name: "app",
+			Env: []corev1.EnvVar{{Name: "FEATURES", ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "features"}, Key: "flags"},
+			}}},
+		}},
+	}
+
+	fixtures := []struct {
+		kind     string
+		name     string
+		expected bool
+	}{
+		{"ConfigMap", "app-config", true},
+		{"ConfigMap", "features", true},
+		{"Secret", "app-tls", true},
+		{"Secret", "registry", true},
+		{"Secret", "db", true},
+		{"Secret", "app-config", false},
+		{"ConfigMap", "db", false},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.kind+"/"+fixture.name, func(t *testing.T) {
+			require.Equal(t, fixture.expected, references(spec, fixture.kind, fixture.name))
+		})
+	}
+}
+
+func TestSecretRedactor(t *testing.T) {
+	r := secretRedactor(&diff.Redactor{Key: []byte("test")})
+
+	before, err := r.Redact(&corev1.Secret{Data: map[string][]byte{"password": []byte("foo")}})
+	require.NoError(t, err)
+	after, err := r.Redact(&corev1.Secret{Data: map[string][]byte{"password": []byte("bar"), "user": []byte("admin")}})
+	require.NoError(t, err)
+
+	changes, err := diff.Diff(before, after)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, "data.password", changes[0].Path)
+	require.Equal(t, "data.user", changes[1].Path)
+	for _, change := range changes {
+		require.NotContains(t, change.To, "YWRtaW4=")
+		require.Regexp(t, `^<redacted:[0-9a-f]{8}>$`, change.To)
+	}
+}
+
+func TestIgnoredConfigMap(t *testing.T) {
+	cm := func(ns, name string, annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, Annotations: annotations}}
+	}
+	require.True(t, ignoredConfigMap(cm("kube-system", "cluster-autoscaler-status", nil)))
+	require.True(t, ignoredConfigMap(cm("default", "app-lock", map[string]string{leaderAnnotation: "{}"})))
+	require.False(t, ignoredConfigMap(cm("default", "cluster-autoscaler-status", nil)))
+	require.False(t, ignoredConfigMap(cm("default", "app-config", nil)))
+}
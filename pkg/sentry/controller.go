@@ -2,6 +2,8 @@ package sentry
 
 import (
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/j2gg0s/kubenotify/pkg/notify"
@@ -11,28 +13,47 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
+	autoscalinginformers "k8s.io/client-go/informers/autoscaling/v2beta2"
+	batchinformers "k8s.io/client-go/informers/batch/v1"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2beta2"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
 type Controller struct {
+	// Options is fixed after New, see Reload for reloadable options.
 	*Options
 
-	notifyFunc notify.NotifyFunc
+	current atomic.Value
 
 	podLister corelisters.PodLister
 	rsLister  appslisters.ReplicaSetLister
 	dLister   appslisters.DeploymentLister
 	ssLister  appslisters.StatefulSetLister
 	dsLister  appslisters.DaemonSetLister
+	cjLister  batchlisters.CronJobLister
+	jobLister batchlisters.JobLister
+	hpaLister autoscalinglisters.HorizontalPodAutoscalerLister
 
 	crLister appslisters.ControllerRevisionLister
 
+	// eventIndexer is warning Event indexed by involved object, see warnings
+	eventIndexer cache.Indexer
+
+	// customs is custom resource keyed by group kind, see AddCustomResource and customKind
+	customMu sync.RWMutex
+	customs  map[string]*customResource
+
 	hasSynced func() bool
 
+	rollouts    *rollouts
+	autoscalers *autoscalers
+	podAlerts   *podAlerts
+
 	queue workqueue.RateLimitingInterface
 }
 
@@ -42,8 +63,15 @@ func New(
 	dInformer appsinformers.DeploymentInformer,
 	ssInformer appsinformers.StatefulSetInformer,
 	dsInformer appsinformers.DaemonSetInformer,
+	cjInformer batchinformers.CronJobInformer,
+	jobInformer batchinformers.JobInformer,
+	cmInformer coreinformers.ConfigMapInformer,
+	secretInformer coreinformers.SecretInformer,
+	hpaInformer autoscalinginformers.HorizontalPodAutoscalerInformer,
+	nodeInformer coreinformers.NodeInformer,
+	eventInformer coreinformers.EventInformer,
 	crInformer appsinformers.ControllerRevisionInformer,
-	notifyFunc notify.NotifyFunc,
+	notifier notify.Notifier,
 	opts ...Option,
 ) (*Controller, error) {
 	options := newOptions()
@@ -53,8 +81,6 @@ func New(
 	ctl := Controller{
 		Options: options,
 
-		notifyFunc: notifyFunc,
-
 		podLister: podInformer.Lister(),
 		rsLister:  rsInformer.Lister(),
 		dLister:   dInformer.Lister(),
@@ -63,6 +89,11 @@ func New(
 
 		hasSynced: podInformer.Informer().HasSynced,
 
+		rollouts:    newRollouts(),
+		autoscalers: newAutoscalers(),
+		podAlerts:   newPodAlerts(),
+		customs:     map[string]*customResource{},
+
 		queue: workqueue.NewNamedRateLimitingQueue(
 			workqueue.NewMaxOfRateLimiter(
 				workqueue.NewItemExponentialFailureRateLimiter(
@@ -75,27 +106,96 @@ func New(
 		),
 	}
 
+	ctl.current.Store(&reloadable{Options: options, notifier: notifier})
+
+	var err error
+	if ctl.eventIndexer, err = addEventIndexer(eventInformer.Informer()); err != nil {
+		return nil, err
+	}
+
 	// watch pod & replicaset
-	_ = podInformer.Informer()
+	if options.watches("Pod") {
+		podInformer.Informer().AddEventHandler(&podHandler{ctl: &ctl})
+	} else {
+		_ = podInformer.Informer()
+	}
 	_ = rsInformer.Informer()
-	if ctl.EnableRevision {
+	// NOTE: start of DaemonSet rollout is the creation of its ControllerRevision
+	if ctl.EnableRevision || options.watches("DaemonSet") {
 		ctl.crLister = crInformer.Lister()
 		_ = crInformer.Informer()
 	}
 
-	if len(options.IncludeResources) == 0 || options.IncludeResources["Deployment"] {
+	if options.watches("Deployment") {
 		dInformer.Informer().AddEventHandler(&ctl)
 	}
-	if len(options.IncludeResources) == 0 || options.IncludeResources["StatefulSet"] {
+	if options.watches("StatefulSet") {
 		ssInformer.Informer().AddEventHandler(&ctl)
 	}
-	if len(options.IncludeResources) == 0 || options.IncludeResources["DaemonSet"] {
+	if options.watches("DaemonSet") {
 		dsInformer.Informer().AddEventHandler(&ctl)
 	}
+	// NOTE: Lister register informer, which require permission to list
+	if options.watches("CronJob") {
+		ctl.cjLister = cjInformer.Lister()
+		cjInformer.Informer().AddEventHandler(&ctl)
+	}
+	if options.watches("Job") {
+		ctl.jobLister = jobInformer.Lister()
+		jobInformer.Informer().AddEventHandler(&ctl)
+	}
+	if options.watches("ConfigMap") {
+		cmInformer.Informer().AddEventHandler(&ctl)
+	}
+	if options.watches("Secret") {
+		secretInformer.Informer().AddEventHandler(&ctl)
+	}
+	if options.watches("HorizontalPodAutoscaler") {
+		ctl.hpaLister = hpaInformer.Lister()
+		hpaInformer.Informer().AddEventHandler(&ctl)
+	}
+	if options.watches("Node") {
+		nodeInformer.Informer().AddEventHandler(&nodeHandler{ctl: &ctl})
+	}
 
 	return &ctl, nil
 }
 
+// reloadable is swapped atomically by Reload.
+type reloadable struct {
+	*Options
+	notifier notify.Notifier
+}
+
+func (ctl *Controller) load() *reloadable {
+	return ctl.current.Load().(*reloadable)
+}
+
+// Reload swap notifier and options without restarting informers.
+// Only these options are reloadable:
+// Excludes, Includes, Redactor, IncludeNamespaces, IgnoreCreatedBefore, IgnoreScaling, ImageLink and Debug.
+func (ctl *Controller) Reload(notifier notify.Notifier, opts ...Option) {
+	options := *ctl.Options
+	options.Excludes = nil
+	options.Includes = nil
+	options.IncludeNamespaces = nil
+	options.ImageLink = nil
+	options.Redactor = defaultRedactor
+	options.IgnoreCreatedBefore = newOptions().IgnoreCreatedBefore
+	options.IgnoreScaling = false
+	options.Debug = false
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// keep options which require restarting informers
+	options.IncludeResources = ctl.Options.IncludeResources
+	options.EnableRevision = ctl.Options.EnableRevision
+	options.LogTail = ctl.Options.LogTail
+
+	ctl.current.Store(&reloadable{Options: &options, notifier: notifier})
+}
+
 func (ctl *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer runtime.HandleCrash()
 	defer ctl.queue.ShutDown()
@@ -0,0 +1,187 @@
+package sentry
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// CustomResource is the resource watched by dynamic informer, e.g. CRD.
+type CustomResource struct {
+	GVR schema.GroupVersionResource
+
+	// Ready, jsonpath of readiness, rollout is tracked like Deployment if set,
+	// complete once the value equal to ReadyValue after generation is observed.
+	Ready      *jsonpath.JSONPath
+	ReadyValue string
+}
+
+// NewCustomResource parse resource as resource.version.group, e.g. rollouts.v1alpha1.argoproj.io,
+// and ready as jsonpath, e.g. {.status.conditions[?(@.type=="Ready")].status}, readyValue default True.
+func NewCustomResource(resource string, ready string, readyValue string) (CustomResource, error) {
+	gvr, _ := schema.ParseResourceArg(resource)
+	if gvr == nil || gvr.Version == "" {
+		return CustomResource{}, fmt.Errorf("invalid resource %s: expect resource.version.group", resource)
+	}
+
+	cr := CustomResource{GVR: *gvr, ReadyValue: readyValue}
+	if cr.ReadyValue == "" {
+		cr.ReadyValue = "True"
+	}
+	if ready != "" {
+		cr.Ready = jsonpath.New(resource).AllowMissingKeys(true)
+		if err := cr.Ready.Parse(ready); err != nil {
+			return CustomResource{}, fmt.Errorf("parse jsonpath %s: %w", ready, err)
+		}
+	}
+	return cr, nil
+}
+
+type customResource struct {
+	CustomResource
+	lister cache.GenericLister
+
+	// NOTE: jsonpath is not safe for concurrent use
+	mu sync.Mutex
+}
+
+// AddCustomResource watch resource by informer, e.g. from dynamicinformer.
+func (ctl *Controller) AddCustomResource(cr CustomResource, informer informers.GenericInformer) {
+	informer.Informer().AddEventHandler(&customHandler{
+		ctl: ctl,
+		cr:  &customResource{CustomResource: cr, lister: informer.Lister()},
+	})
+}
+
+// customKind return group kind of custom resource, e.g. Rollout.argoproj.io,
+// which is used as kind of queue key to not conflict with built-in kinds and other groups.
+func customKind(u *unstructured.Unstructured) string {
+	return u.GroupVersionKind().GroupKind().String()
+}
+
+// customHandler record kind of custom resource, which is unknown until object received,
+// so that Inspect can find lister by group kind.
+type customHandler struct {
+	ctl *Controller
+	cr  *customResource
+}
+
+func (h *customHandler) observe(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	h.ctl.customMu.Lock()
+	defer h.ctl.customMu.Unlock()
+	kind := customKind(u)
+	if cr, ok := h.ctl.customs[kind]; ok && cr != h.cr {
+		log.Warn().Msgf("kind %s of %s conflict with %s", kind, h.cr.GVR, cr.GVR)
+		return
+	}
+	h.ctl.customs[kind] = h.cr
+}
+
+func (h *customHandler) OnAdd(obj interface{}) {
+	h.observe(obj)
+	h.ctl.OnAdd(obj)
+}
+
+func (h *customHandler) OnUpdate(before, after interface{}) {
+	h.observe(after)
+	h.ctl.OnUpdate(before, after)
+}
+
+func (h *customHandler) OnDelete(obj interface{}) {
+	h.observe(obj)
+	h.ctl.OnDelete(obj)
+}
+
+func (ctl *Controller) customResource(kind string) *customResource {
+	ctl.customMu.RLock()
+	defer ctl.customMu.RUnlock()
+	return ctl.customs[kind]
+}
+
+func (ctl *Controller) inspectCustom(cr *customResource, kind, key, ns, name string) error {
+	if cr.Ready == nil {
+		return nil
+	}
+
+	var raw interface{}
+	var err error
+	if ns == "" {
+		raw, err = cr.lister.Get(name)
+	} else {
+		raw, err = cr.lister.ByNamespace(ns).Get(name)
+	}
+	if err != nil {
+		return fmt.Errorf("get %s(%s): %w", kind, key, err)
+	}
+	obj, ok := raw.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected %T of %s(%s)", raw, kind, key)
+	}
+
+	cr.mu.Lock()
+	status := customStatus(cr.CustomResource, obj)
+	cr.mu.Unlock()
+	status.deadline = ctl.RolloutDeadline
+
+	state, phases := ctl.rollouts.transit(queueKey(kind, key), obj.GetUID(), status, time.Now())
+	for _, phase := range phases {
+		e := rolloutEvent(obj.GetKind(), obj, status, state, phase)
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
+		}
+	}
+
+	if state.done() {
+		return nil
+	}
+	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+}
+
+// customStatus is complete once status.observedGeneration, if any, catch up
+// and the value of Ready equal to ReadyValue.
+func customStatus(cr CustomResource, obj *unstructured.Unstructured) rolloutStatus {
+	status := rolloutStatus{generation: obj.GetGeneration(), desired: 1}
+
+	observed, found, err := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if err == nil && found && observed < obj.GetGeneration() {
+		return status
+	}
+
+	results, err := cr.Ready.FindResults(obj.Object)
+	if err != nil {
+		status.message = err.Error()
+		return status
+	}
+	values := []string{}
+	for _, result := range results {
+		for _, v := range result {
+			values = append(values, fmt.Sprint(v.Interface()))
+		}
+	}
+	value := strings.Join(values, ",")
+	status.message = fmt.Sprintf("ready is %q", value)
+	if value == cr.ReadyValue {
+		status.complete = true
+		status.ready = 1
+		status.available = 1
+		status.message = ""
+	}
+	return status
+}
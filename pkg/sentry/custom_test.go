@@ -0,0 +1,84 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestNewCustomResource(t *testing.T) {
+	cr, err := NewCustomResource("rollouts.v1alpha1.argoproj.io", "{.status.phase}", "Healthy")
+	require.NoError(t, err)
+	require.Equal(t, schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}, cr.GVR)
+	require.NotNil(t, cr.Ready)
+
+	cr, err = NewCustomResource("scaledobjects.v1alpha1.keda.sh", "", "")
+	require.NoError(t, err)
+	require.Nil(t, cr.Ready)
+	require.Equal(t, "True", cr.ReadyValue)
+
+	_, err = NewCustomResource("rollouts", "", "")
+	require.Error(t, err)
+	_, err = NewCustomResource("rollouts.v1alpha1.argoproj.io", "{.status.phase", "")
+	require.Error(t, err)
+}
+
+func TestCustomStatus(t *testing.T) {
+	cr, err := NewCustomResource("services.v1.serving.knative.dev", `{.status.conditions[?(@.type=="Ready")].status}`, "")
+	require.NoError(t, err)
+
+	service := func(generation, observed int64, ready string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "hello", "generation": generation},
+			"status": map[string]interface{}{
+				"observedGeneration": observed,
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "ConfigurationsReady", "status": "True"},
+					map[string]interface{}{"type": "Ready", "status": ready},
+				},
+			},
+		}}
+	}
+
+	fixtures := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		complete bool
+	}{
+		{"ready", service(2, 2, "True"), true},
+		{"not ready", service(2, 2, "Unknown"), false},
+		{"not observed", service(3, 2, "True"), false},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			status := customStatus(cr, fixture.obj)
+			require.Equal(t, fixture.complete, status.complete)
+			require.Equal(t, fixture.obj.GetGeneration(), status.generation)
+		})
+	}
+}
+
+func TestCustomKind(t *testing.T) {
+	object := func(apiVersion, kind string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"namespace": "default", "name": "app"},
+		}}
+	}
+
+	ctl := &Controller{customs: map[string]*customResource{}}
+	a := &customHandler{ctl: ctl, cr: &customResource{}}
+	b := &customHandler{ctl: ctl, cr: &customResource{}}
+	a.observe(object("apps.example.com/v1", "Deployment"))
+	b.observe(object("deploy.example.io/v1beta1", "Deployment"))
+
+	require.Equal(t, "Deployment.apps.example.com", customKind(object("apps.example.com/v1", "Deployment")))
+	require.Same(t, a.cr, ctl.customResource("Deployment.apps.example.com"))
+	require.Same(t, b.cr, ctl.customResource("Deployment.deploy.example.io"))
+	require.Nil(t, ctl.customResource("Deployment"))
+}
@@ -1,15 +1,18 @@
 package sentry
 
 import (
-	"encoding/json"
-	"fmt"
-	"strings"
 	"time"
 
+	"github.com/j2gg0s/kubenotify/pkg/diff"
+	"github.com/j2gg0s/kubenotify/pkg/notify"
 	"github.com/j2gg0s/kubenotify/pkg/util"
-	"github.com/r3labs/diff"
 	"github.com/rs/zerolog/log"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metaapi "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -44,6 +47,11 @@ func (ctl *Controller) onChange(before, after interface{}) {
 	}
 
 	kind := util.KindAccessor(obj)
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		// NOTE: custom resource may have the same kind as built-in or other group
+		kind = customKind(u)
+	}
+	cur := ctl.load()
 
 	meta, err := metaapi.Accessor(obj)
 	if err != nil {
@@ -52,16 +60,16 @@ func (ctl *Controller) onChange(before, after interface{}) {
 	}
 
 	if before == nil &&
-		time.Since(meta.GetCreationTimestamp().Time) > ctl.IgnoreCreatedBefore {
+		time.Since(meta.GetCreationTimestamp().Time) > cur.IgnoreCreatedBefore {
 
 		// NOTE: when restart
-		log.Debug().Msgf("ignore %T(%s): create before %v", obj, key, ctl.IgnoreCreatedBefore)
+		log.Debug().Msgf("ignore %T(%s): create before %v", obj, key, cur.IgnoreCreatedBefore)
 		return
 	}
 
-	if len(ctl.IncludeNamespaces) > 0 &&
+	if len(cur.IncludeNamespaces) > 0 &&
 		meta.GetNamespace() != "" &&
-		!ctl.IncludeNamespaces[meta.GetNamespace()] {
+		!cur.IncludeNamespaces[meta.GetNamespace()] {
 
 		log.Debug().Msgf(
 			"ignore %T(%s): namespace %s",
@@ -70,99 +78,232 @@ func (ctl *Controller) onChange(before, after interface{}) {
 		return
 	}
 
-	var msg string
+	e := notify.Event{
+		Kind:            util.KindAccessor(obj),
+		Namespace:       meta.GetNamespace(),
+		Name:            meta.GetName(),
+		UID:             string(meta.GetUID()),
+		Labels:          meta.GetLabels(),
+		ResourceVersion: meta.GetResourceVersion(),
+		Timestamp:       time.Now(),
+	}
+	if cj, ok := obj.(*batchv1.CronJob); ok {
+		e.Job = cronJob(cj)
+	}
+	if secret, ok := obj.(*corev1.Secret); ok && ignoredSecretTypes[secret.Type] {
+		log.Debug().Msgf("ignore %s(%s): type %s", kind, key, secret.Type)
+		return
+	}
+	if cm, ok := obj.(*corev1.ConfigMap); ok && ignoredConfigMap(cm) {
+		log.Debug().Msgf("ignore %s(%s): changed by controller", kind, key)
+		return
+	}
+	if kind == "ConfigMap" || kind == "Secret" {
+		if e.Consumers, err = ctl.consumers(kind, meta.GetNamespace(), meta.GetName()); err != nil {
+			log.Warn().Err(err).Msgf("access consumers of %s(%s)", kind, key)
+		}
+	}
+
 	if before == nil {
-		msg = fmt.Sprintf(
-			"%s(%s) CreatedAt(%s)",
-			kind, key,
-			meta.GetCreationTimestamp().Format(ctl.TimeFormat))
+		e.Action = notify.Created
+		e.Timestamp = meta.GetCreationTimestamp().Time
+
+		if job, ok := obj.(*batchv1.Job); ok {
+			// NOTE: notify JobStarted instead,
+			// and track the run even if it is finished before inspected
+			status := jobStatus(job)
+			status.complete, status.failed = false, false
+			ctl.rollouts.transit(queueKey(kind, key), job.UID, status, time.Now())
+			e = jobEvent(kind, job, notify.JobStarted)
+		}
 	} else if after == nil {
-		msg = fmt.Sprintf(
-			"%s(%s) DeletedAt(%s)",
-			kind, key,
-			time.Now().Format(ctl.TimeFormat))
+		e.Action = notify.Deleted
+		ctl.rollouts.forget(queueKey(kind, key))
+		ctl.autoscalers.forget(queueKey(kind, key))
+
+		if ref := metav1.GetControllerOf(meta); kind == "Job" && ref != nil && ref.Kind == "CronJob" {
+			// NOTE: deleted by history limit of CronJob
+			log.Debug().Msgf("ignore %s(%s): deleted by CronJob %s", kind, key, ref.Name)
+			return
+		}
 	} else {
-		changes, err := diffAsMap(before, after)
+		e.Action = notify.Updated
+
+		redactor := cur.Redactor
+		if kind == "Secret" {
+			redactor = secretRedactor(redactor)
+		}
+		bm, err := redactor.Redact(before)
+		if err != nil {
+			log.Warn().Err(err).Msgf("redact")
+			return
+		}
+		am, err := redactor.Redact(after)
+		if err != nil {
+			log.Warn().Err(err).Msgf("redact")
+			return
+		}
+		changes, err := diff.Diff(bm, am)
 		if err != nil {
 			log.Warn().Err(err).Msgf("diff")
 			return
 		}
-		msgs := []string{fmt.Sprintf("%s(%s) ChangedAt(%s)", kind, key, time.Now().Format(ctl.TimeFormat))}
+		var scaled *notify.Event
+		kept, excluded := []diff.Change{}, []diff.Change{}
 		for _, change := range changes {
-			path := []byte(strings.Join(change.Path, "."))
+			path := []byte(change.Path)
+
+			if change.Path == "spec.replicas" && (kind == "Deployment" || kind == "StatefulSet") {
+				// NOTE: report scaling separately, regardless of excludes
+				se := ctl.scalingEvent(kind, meta, toInt32(change.From), toInt32(change.To))
+				scaled = &se
+				excluded = append(excluded, change)
+				continue
+			}
 
-			if len(ctl.Excludes) > 0 {
+			if len(cur.Excludes) > 0 {
 				exclude := false
-				for _, regex := range ctl.Excludes {
+				for _, regex := range cur.Excludes {
 					if regex.Match(path) {
 						exclude = true
 						break
 					}
 				}
 				if exclude {
+					excluded = append(excluded, change)
 					continue
 				}
 			}
 
-			if len(ctl.Includes) > 0 {
+			if len(cur.Includes) > 0 {
 				include := false
-				for _, regex := range ctl.Includes {
+				for _, regex := range cur.Includes {
 					if regex.Match(path) {
 						include = true
 						break
 					}
 				}
 				if !include {
+					excluded = append(excluded, change)
 					continue
 				}
 			}
 
-			msgs = append(
-				msgs,
-				fmt.Sprintf("%s(%v - %v)", string(path), change.From, change.To))
+			kept = append(kept, change)
+			manager, operation := changeManager(meta, change.Path)
+			e.Changes = append(e.Changes, notify.Change{
+				Path:      string(path),
+				From:      change.From,
+				To:        change.To,
+				Delta:     change.Delta,
+				Manager:   manager,
+				Operation: operation,
+			})
 		}
-		if len(msgs) == 1 {
+		e.Managers = changeManagers(e.Changes)
+		e.Images = imageChanges(e.Changes, cur.ImageLink)
+		if len(e.Changes) > 0 {
+			hunks, err := unifiedHunks(bm, am, kept, excluded, e.Images)
+			if err != nil {
+				log.Warn().Err(err).Msgf("group changes of %s(%s)", kind, key)
+			}
+			e.Hunks = hunks
+		}
+
+		if d, ok := after.(*appsv1.Deployment); ok {
+			rollback, err := ctl.detectRollback(before.(*appsv1.Deployment), d)
+			if err != nil {
+				log.Warn().Err(err).Msgf("detect rollback of %s(%s)", kind, key)
+			} else if rollback != nil {
+				e.Action = notify.RolledBack
+				e.Rollback = rollback
+			}
+		}
+
+		if scaled != nil && !cur.IgnoreScaling {
+			if err := cur.notifier.Notify(*scaled); err != nil {
+				log.Warn().Err(err).Msgf("notify %s(%s-%s)", kind, key, meta.GetResourceVersion())
+			}
+		}
+
+		if len(e.Changes) == 0 && e.Action != notify.RolledBack && scaled != nil {
+			// NOTE: generation changed by scaling, which is not a rollout
+			ctl.rollouts.skip(queueKey(kind, key), meta.GetUID(), meta.GetGeneration())
+		}
+
+		if len(e.Changes) == 0 && e.Action != notify.RolledBack {
+			// NOTE: status changed, keep tracking rollout,
+			// Job is tracked even if started before first observed
+			if ctl.rollouts.active(queueKey(kind, key)) ||
+				(kind == "Job" && !ctl.rollouts.done(queueKey(kind, key))) ||
+				kind == "HorizontalPodAutoscaler" {
+				ctl.queue.Add(queueKey(kind, key))
+			}
 			log.Debug().Msgf("ignore %s(%s-%s)", kind, key, meta.GetResourceVersion())
 			return
 		}
-
-		msg = strings.Join(msgs, " ")
 	}
 
 	log.Debug().Msgf("enqueue %s(%s-%s)", kind, key, meta.GetResourceVersion())
-	ctl.queue.Add(fmt.Sprintf("%s;%s", kind, key))
+	ctl.queue.Add(queueKey(kind, key))
 
-	if ctl.Debug {
-		msg = fmt.Sprintf("%s ResourceVersion(%s)", msg, meta.GetResourceVersion())
+	if err := cur.notifier.Notify(e); err != nil {
+		log.Warn().Err(err).Msgf("notify %s(%s-%s)", kind, key, meta.GetResourceVersion())
 	}
+}
 
-	if err := ctl.notifyFunc(msg); err != nil {
-		log.Warn().Err(err).Msgf("notify msg(%s)", msg)
+// changeManagers return distinct managers of changes in order.
+func changeManagers(changes []notify.Change) []string {
+	seen := map[string]bool{}
+	managers := []string{}
+	for _, change := range changes {
+		if change.Manager == "" || seen[change.Manager] {
+			continue
+		}
+		seen[change.Manager] = true
+		managers = append(managers, change.Manager)
 	}
+	return managers
 }
 
-func diffAsMap(before, after interface{}) ([]diff.Change, error) {
-	bm, err := convertToMap(before)
-	if err != nil {
-		return nil, fmt.Errorf("convert %T to map: %w", before, err)
+// toInt32 convert number decoded from json, 0 if not a number.
+func toInt32(v interface{}) int32 {
+	switch n := v.(type) {
+	case float64:
+		return int32(n)
+	case int64:
+		return int32(n)
+	case int32:
+		return n
+	case int:
+		return int32(n)
 	}
-	am, err := convertToMap(after)
-	if err != nil {
-		return nil, fmt.Errorf("convert %T to map: %w", after, err)
+	return 0
+}
+
+// unifiedHunks group changes by their parent map or list,
+// image is summarized separately, so that its parent is not grouped.
+func unifiedHunks(before, after interface{}, changes, excluded []diff.Change, images []notify.ImageChange) ([]notify.Change, error) {
+	paths := map[string]bool{}
+	for _, image := range images {
+		paths[image.Path] = true
 	}
-	changes, err := diff.Diff(bm, am)
-	if err != nil {
-		return nil, fmt.Errorf("diff %T vs %T: %w", before, after, err)
+	kept := make([]diff.Change, 0, len(changes))
+	for _, change := range changes {
+		if paths[change.Path] {
+			excluded = append(excluded, change)
+		} else {
+			kept = append(kept, change)
+		}
 	}
-	return changes, nil
-}
 
-func convertToMap(obj interface{}) (map[string]interface{}, error) {
-	m := map[string]interface{}{}
-	if b, err := json.Marshal(obj); err != nil {
-		return nil, err
-	} else if err := json.Unmarshal(b, &m); err != nil {
+	hunks, err := diff.Hunks(before, after, kept, excluded)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	changed := make([]notify.Change, 0, len(hunks))
+	for _, hunk := range hunks {
+		changed = append(changed, notify.Change{Path: hunk.Path, From: hunk.From, To: hunk.To, Delta: hunk.Delta})
+	}
+	return changed, nil
 }
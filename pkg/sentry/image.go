@@ -0,0 +1,100 @@
+package sentry
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"text/template"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/j2gg0s/kubenotify/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+var imagePath = regexp.MustCompile(`(?:containers|initContainers|ephemeralContainers)\[([^\]]+)\]\.image$`)
+
+const (
+	ImageTag        = "Tag"
+	ImageDigest     = "Digest"
+	ImageRepository = "Repository"
+	ImageRegistry   = "Registry"
+)
+
+// ImageLink is the data to render link of image change, e.g.
+// https://github.com/example/{{ .Repository }}/compare/{{ .FromTag }}...{{ .ToTag }}
+type ImageLink struct {
+	Container  string
+	Registry   string
+	Repository string
+	FromTag    string
+	ToTag      string
+	FromDigest string
+	ToDigest   string
+}
+
+// imageChanges summary image change of containers, link is optional.
+func imageChanges(changes []notify.Change, link *template.Template) []notify.ImageChange {
+	images := []notify.ImageChange{}
+	for _, change := range changes {
+		match := imagePath.FindStringSubmatch(change.Path)
+		if match == nil {
+			continue
+		}
+		from, fok := change.From.(string)
+		to, tok := change.To.(string)
+		if !fok || !tok {
+			continue
+		}
+
+		image := imageChange(match[1], from, to)
+		image.Path = change.Path
+		if link != nil {
+			fi, ti := util.ParseImage(from), util.ParseImage(to)
+			buf := bytes.Buffer{}
+			err := link.Execute(&buf, ImageLink{
+				Container:  image.Container,
+				Registry:   ti.Registry,
+				Repository: ti.Repository,
+				FromTag:    fi.Tag,
+				ToTag:      ti.Tag,
+				FromDigest: fi.Digest,
+				ToDigest:   ti.Digest,
+			})
+			if err != nil {
+				log.Warn().Err(err).Msgf("render link of %s", image.Summary)
+			} else {
+				image.Link = buf.String()
+			}
+		}
+		images = append(images, image)
+	}
+	return images
+}
+
+func imageChange(container, from, to string) notify.ImageChange {
+	fi, ti := util.ParseImage(from), util.ParseImage(to)
+	image := notify.ImageChange{Container: container, From: from, To: to}
+
+	switch {
+	case fi.Registry != ti.Registry:
+		image.Type = ImageRegistry
+		image.Summary = fmt.Sprintf("%s: %s → %s", container, from, to)
+	case fi.Repository != ti.Repository:
+		image.Type = ImageRepository
+		image.Summary = fmt.Sprintf("%s: %s → %s", container, from, to)
+	case fi.Tag != ti.Tag:
+		image.Type = ImageTag
+		image.Summary = fmt.Sprintf("%s: %s → %s", container, orNone(fi.Tag), orNone(ti.Tag))
+	default:
+		image.Type = ImageDigest
+		image.Summary = fmt.Sprintf("%s: %s → %s", container, orNone(fi.ShortDigest()), orNone(ti.ShortDigest()))
+	}
+	return image
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
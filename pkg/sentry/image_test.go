@@ -0,0 +1,34 @@
+package sentry
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageChanges(t *testing.T) {
+	link := template.Must(template.New("link").Parse(
+		`https://github.com/example/{{ .Repository }}/compare/{{ .FromTag }}...{{ .ToTag }}`))
+
+	changes := []notify.Change{
+		{Path: "spec.template.spec.containers[app].image", From: "registry.example.com/app:v1.2.3", To: "registry.example.com/app:v1.2.4"},
+		{Path: "spec.template.spec.containers[app].env[A].value", From: "a", To: "b"},
+		{Path: "spec.template.spec.initContainers[init].image", From: "init@sha256:0123456789abcdef0", To: "init@sha256:fedcba9876543210f"},
+		{Path: "spec.template.spec.containers[proxy].image", From: "docker.io/envoy:v1", To: "gcr.io/envoy:v1"},
+	}
+
+	images := imageChanges(changes, link)
+	require.Len(t, images, 3)
+
+	require.Equal(t, ImageTag, images[0].Type)
+	require.Equal(t, "app: v1.2.3 → v1.2.4", images[0].Summary)
+	require.Equal(t, "https://github.com/example/app/compare/v1.2.3...v1.2.4", images[0].Link)
+
+	require.Equal(t, ImageDigest, images[1].Type)
+	require.Equal(t, "init: sha256:0123456789ab → sha256:fedcba987654", images[1].Summary)
+
+	require.Equal(t, ImageRegistry, images[2].Type)
+	require.Equal(t, "proxy: docker.io/envoy:v1 → gcr.io/envoy:v1", images[2].Summary)
+}
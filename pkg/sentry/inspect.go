@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/j2gg0s/kubenotify/pkg/notify"
 	"github.com/j2gg0s/kubenotify/pkg/util"
 	"github.com/rs/zerolog/log"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
@@ -24,77 +26,358 @@ func (ctl *Controller) Inspect(kind, key string) error {
 		return ErrNotSynced
 	}
 
-	var owner types.UID
-	var desired, ready int32
-	var age time.Duration
 	switch kind {
 	case "Deployment":
-		obj, err := ctl.dLister.Deployments(ns).Get(name)
-		if err != nil {
-			return fmt.Errorf("get deployment(%s): %w", key, err)
-		}
-		desired, ready = obj.Status.Replicas, obj.Status.ReadyReplicas
+		return ctl.inspectDeployment(kind, key, ns, name)
+	case "StatefulSet":
+		return ctl.inspectStatefulSet(kind, key, ns, name)
+	case "DaemonSet":
+		return ctl.inspectDaemonSet(kind, key, ns, name)
+	case "CronJob":
+		// NOTE: nothing to track, run of CronJob is inspected as Job
+		return nil
+	case "ConfigMap", "Secret":
+		return nil
+	case "HorizontalPodAutoscaler":
+		return ctl.inspectAutoscaler(kind, key, ns, name)
+	case "Job":
+		return ctl.inspectJob(kind, key, ns, name)
+	case "Pod":
+		return ctl.inspectPod(kind, key, ns, name)
+	}
+	if cr := ctl.customResource(kind); cr != nil {
+		return ctl.inspectCustom(cr, kind, key, ns, name)
+	}
+	return fmt.Errorf("unknown kind: %s", kind)
+}
 
-		if desired != ready {
-			rsList, err := ctl.rsLister.ReplicaSets(ns).List(labels.Everything())
-			if err != nil {
-				return fmt.Errorf("list replicaset(%s): %w", ns, err)
-			}
-			replicaset := (*appsv1.ReplicaSet)(nil)
-			for _, rs := range rsList {
-				for _, owner := range rs.ObjectMeta.OwnerReferences {
-					if owner.UID == obj.ObjectMeta.UID {
-						if replicaset == nil || replicaset.ObjectMeta.CreationTimestamp.Time.Before(rs.ObjectMeta.CreationTimestamp.Time) {
-							replicaset = rs
-						}
-					}
+func (ctl *Controller) inspectDeployment(kind, key, ns, name string) error {
+	obj, err := ctl.dLister.Deployments(ns).Get(name)
+	if err != nil {
+		return fmt.Errorf("get deployment(%s): %w", key, err)
+	}
+
+	status := deploymentStatus(obj)
+	state, phases := ctl.rollouts.transit(queueKey(kind, key), obj.UID, status, time.Now())
+	for _, phase := range phases {
+		e := rolloutEvent(kind, obj, status, state, phase)
+		if phase == rolloutFailed {
+			if rs, err := ctl.newestReplicaSet(obj); err != nil {
+				log.Warn().Err(err).Msgf("access replicaset of %s(%s)", kind, key)
+			} else if rs != nil {
+				e.Age = time.Since(rs.ObjectMeta.CreationTimestamp.Time)
+				if e.Pods, err = ctl.podStatuses(ns, rs.UID, false); err != nil {
+					log.Warn().Err(err).Msgf("access pods of %s(%s)", kind, key)
 				}
-			}
-			if replicaset != nil {
-				owner = replicaset.ObjectMeta.UID
-				age = time.Since(replicaset.ObjectMeta.CreationTimestamp.Time)
+				e.Warnings = ctl.workloadWarnings(ns, state.startedAt, obj.UID, rs.UID)
+				ctl.attachLogs(ns, e.Pods)
+			} else {
+				e.Warnings = ctl.workloadWarnings(ns, state.startedAt, obj.UID)
 			}
 		}
 
-	case "StatefulSet":
-		obj, err := ctl.ssLister.StatefulSets(ns).Get(name)
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
+		}
+	}
+
+	if state.done() {
+		return nil
+	}
+	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+}
+
+func (ctl *Controller) inspectDaemonSet(kind, key, ns, name string) error {
+	obj, err := ctl.dsLister.DaemonSets(ns).Get(name)
+	if err != nil {
+		return fmt.Errorf("get daemonset(%s): %w", key, err)
+	}
+
+	status := daemonSetStatus(obj)
+	status.deadline = ctl.RolloutDeadline
+	if ctl.crLister != nil {
+		revision, err := ctl.currentRevision(obj.Namespace, obj.UID)
 		if err != nil {
-			return fmt.Errorf("get statefulset(%s): %w", key, err)
+			log.Warn().Err(err).Msgf("access revision of %s(%s)", kind, key)
+		} else if revision != nil {
+			status.startedAt = revision.ObjectMeta.CreationTimestamp.Time
 		}
-		desired, ready = obj.Status.Replicas, obj.Status.ReadyReplicas
-		owner = obj.ObjectMeta.UID
-		age = time.Since(obj.ObjectMeta.CreationTimestamp.Time)
+	}
 
-		if desired != ready && ctl.EnableRevision {
-			revision, err := ctl.crLister.ControllerRevisions(ns).Get(obj.Status.UpdateRevision)
-			if err != nil {
-				return fmt.Errorf("get revision(%s, %s): %w", ns, obj.Status.UpdateRevision, err)
+	state, phases := ctl.rollouts.transit(queueKey(kind, key), obj.UID, status, time.Now())
+	for _, phase := range phases {
+		e := rolloutEvent(kind, obj, status, state, phase)
+		if phase == rolloutFailed {
+			// NOTE: report node since pod of daemonset is bound to node
+			if e.Pods, err = ctl.podStatuses(ns, obj.UID, true); err != nil {
+				log.Warn().Err(err).Msgf("access pods of %s(%s)", kind, key)
 			}
-			age = time.Since(revision.ObjectMeta.CreationTimestamp.Time)
+			e.Warnings = ctl.workloadWarnings(ns, state.startedAt, obj.UID)
+			ctl.attachLogs(ns, e.Pods)
 		}
-	case "DaemonSet":
-		obj, err := ctl.dsLister.DaemonSets(ns).Get(name)
+
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
+		}
+	}
+
+	if state.done() {
+		return nil
+	}
+	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+}
+
+func (ctl *Controller) inspectStatefulSet(kind, key, ns, name string) error {
+	obj, err := ctl.ssLister.StatefulSets(ns).Get(name)
+	if err != nil {
+		return fmt.Errorf("get statefulset(%s): %w", key, err)
+	}
+
+	status := statefulSetStatus(obj)
+	status.deadline = ctl.RolloutDeadline
+	if ctl.EnableRevision && obj.Status.UpdateRevision != "" {
+		revision, err := ctl.crLister.ControllerRevisions(ns).Get(obj.Status.UpdateRevision)
 		if err != nil {
-			return fmt.Errorf("get daemonset(%s): %w", key, err)
+			log.Warn().Err(err).Msgf("get revision(%s, %s)", ns, obj.Status.UpdateRevision)
+		} else {
+			status.startedAt = revision.ObjectMeta.CreationTimestamp.Time
+		}
+	}
+	if !status.complete {
+		status.blocking = ctl.statefulSetBlocking(obj, status)
+	}
+
+	state, phases := ctl.rollouts.transit(queueKey(kind, key), obj.UID, status, time.Now())
+	for _, phase := range phases {
+		e := rolloutEvent(kind, obj, status, state, phase)
+		if phase == rolloutFailed {
+			if e.Pods, err = ctl.podStatuses(ns, obj.UID, false); err != nil {
+				log.Warn().Err(err).Msgf("access pods of %s(%s)", kind, key)
+			}
+			e.Warnings = ctl.workloadWarnings(ns, state.startedAt, obj.UID)
+			ctl.attachLogs(ns, e.Pods)
+		}
+
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
 		}
-		desired, ready = obj.Status.DesiredNumberScheduled, obj.Status.NumberReady
-		owner = obj.ObjectMeta.UID
-		age = time.Since(obj.ObjectMeta.CreationTimestamp.Time)
-		// TODO: daemonset changed at?
 	}
 
-	msg := fmt.Sprintf(
-		"%s(%s) Age(%s) READY(%d/%d)",
-		kind, key, util.PrettyDuration(age, 2), ready, desired)
-	if desired == ready {
-		log.Debug().Msgf(msg)
+	if state.done() {
 		return nil
 	}
+	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+}
+
+// statefulSetStatus follow `kubectl rollout status`,
+// pods with ordinal less than partition are not updated,
+// and pods are updated only when deleted manually if OnDelete.
+func statefulSetStatus(obj *appsv1.StatefulSet) rolloutStatus {
+	desired := int32(1)
+	if obj.Spec.Replicas != nil {
+		desired = *obj.Spec.Replicas
+	}
+
+	status := rolloutStatus{
+		generation:      obj.Generation,
+		desired:         desired,
+		updated:         obj.Status.UpdatedReplicas,
+		ready:           obj.Status.ReadyReplicas,
+		available:       obj.Status.ReadyReplicas,
+		strategy:        string(obj.Spec.UpdateStrategy.Type),
+		currentRevision: obj.Status.CurrentRevision,
+		updateRevision:  obj.Status.UpdateRevision,
+	}
+	if ru := obj.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+		status.partition = *ru.Partition
+	}
+	if obj.Status.ObservedGeneration < obj.Generation {
+		return status
+	}
+
+	target := desired - status.partition
+	if target < 0 {
+		target = 0
+	}
+	status.complete = status.updated >= target && status.ready == desired
+	if status.partition == 0 {
+		// NOTE: currentRevision is set to updateRevision after all pods updated
+		status.complete = status.complete && status.currentRevision == status.updateRevision
+	}
+
+	return status
+}
+
+// statefulSetBlocking return pods which block the rollout.
+// RollingUpdate update pods from the largest ordinal to partition one by one,
+// so the first pod not updated or not ready is blocking.
+// OnDelete wait all pods not updated to be deleted manually.
+func (ctl *Controller) statefulSetBlocking(obj *appsv1.StatefulSet, status rolloutStatus) []string {
+	blocking := []string{}
+	for ordinal := status.desired - 1; ordinal >= status.partition; ordinal-- {
+		name := fmt.Sprintf("%s-%d", obj.Name, ordinal)
+		pod, err := ctl.podLister.Pods(obj.Namespace).Get(name)
+		if err != nil {
+			// NOTE: pod is being recreated
+			blocking = append(blocking, name)
+		} else if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != status.updateRevision {
+			blocking = append(blocking, name)
+		} else if status.strategy != string(appsv1.OnDeleteStatefulSetStrategyType) && !podReady(pod) {
+			blocking = append(blocking, name)
+		}
+
+		if len(blocking) > 0 && status.strategy != string(appsv1.OnDeleteStatefulSetStrategyType) {
+			break
+		}
+	}
+	return blocking
+}
+
+// daemonSetStatus follow `kubectl rollout status`,
+// OnDelete is complete once observed since nothing will be updated automatically.
+func daemonSetStatus(obj *appsv1.DaemonSet) rolloutStatus {
+	status := rolloutStatus{
+		generation: obj.Generation,
+		desired:    obj.Status.DesiredNumberScheduled,
+		updated:    obj.Status.UpdatedNumberScheduled,
+		ready:      obj.Status.NumberReady,
+		available:  obj.Status.NumberAvailable,
+	}
+	if obj.Status.ObservedGeneration < obj.Generation {
+		return status
+	}
+
+	if obj.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		status.complete = true
+		return status
+	}
 
+	status.complete = status.updated == status.desired &&
+		status.available == status.desired &&
+		obj.Status.NumberUnavailable == 0
+
+	return status
+}
+
+// currentRevision return the ControllerRevision with max revision owned by owner, nil if not found.
+func (ctl *Controller) currentRevision(ns string, owner types.UID) (*appsv1.ControllerRevision, error) {
+	revisions, err := ctl.crLister.ControllerRevisions(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list revision(%s): %w", ns, err)
+	}
+	current := (*appsv1.ControllerRevision)(nil)
+	for _, revision := range revisions {
+		for _, ref := range revision.ObjectMeta.OwnerReferences {
+			if ref.UID == owner && (current == nil || current.Revision < revision.Revision) {
+				current = revision
+			}
+		}
+	}
+	return current, nil
+}
+
+// deploymentStatus follow `kubectl rollout status`.
+func deploymentStatus(obj *appsv1.Deployment) rolloutStatus {
+	desired := int32(1)
+	if obj.Spec.Replicas != nil {
+		desired = *obj.Spec.Replicas
+	}
+
+	status := rolloutStatus{
+		generation: obj.Generation,
+		desired:    desired,
+		updated:    obj.Status.UpdatedReplicas,
+		ready:      obj.Status.ReadyReplicas,
+		available:  obj.Status.AvailableReplicas,
+	}
+	if obj.Status.ObservedGeneration < obj.Generation {
+		return status
+	}
+
+	for _, cond := range obj.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded" {
+			status.failed = true
+			status.reason = cond.Reason
+			status.message = cond.Message
+		}
+	}
+
+	status.complete = status.updated == desired &&
+		obj.Status.Replicas == desired &&
+		status.available == desired
+
+	return status
+}
+
+func rolloutEvent(kind string, meta metav1.Object, status rolloutStatus, state rolloutState, phase rolloutPhase) notify.Event {
+	now := time.Now()
+	if phase == rolloutFailed && status.reason == "" {
+		// NOTE: failed by deadline
+		status.reason = "ProgressDeadlineExceeded"
+		status.message = fmt.Sprintf("not complete after %s", util.PrettyDuration(status.deadline, 2))
+		if status.strategy == string(appsv1.OnDeleteStatefulSetStrategyType) {
+			status.reason = "OnDeleteStalled"
+			status.message = fmt.Sprintf("%s, waiting pods to be deleted", status.message)
+		}
+	}
+	return notify.Event{
+		Kind:            kind,
+		Namespace:       meta.GetNamespace(),
+		Name:            meta.GetName(),
+		UID:             string(meta.GetUID()),
+		Labels:          meta.GetLabels(),
+		Action:          rolloutActions[phase],
+		ResourceVersion: meta.GetResourceVersion(),
+		Timestamp:       now,
+		Age:             now.Sub(state.startedAt),
+		Desired:         status.desired,
+		Ready:           status.ready,
+		Rollout: &notify.Rollout{
+			Generation: status.generation,
+			Desired:    status.desired,
+			Updated:    status.updated,
+			Ready:      status.ready,
+			Available:  status.available,
+			Duration:   now.Sub(state.startedAt),
+			Reason:     status.reason,
+			Message:    status.message,
+
+			Strategy:        status.strategy,
+			Partition:       status.partition,
+			CurrentRevision: status.currentRevision,
+			UpdateRevision:  status.updateRevision,
+			Blocking:        status.blocking,
+		},
+	}
+}
+
+// newestReplicaSet return the newest replicaset owned by deployment, nil if not found.
+func (ctl *Controller) newestReplicaSet(obj *appsv1.Deployment) (*appsv1.ReplicaSet, error) {
+	rsList, err := ctl.rsLister.ReplicaSets(obj.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list replicaset(%s): %w", obj.Namespace, err)
+	}
+	replicaset := (*appsv1.ReplicaSet)(nil)
+	for _, rs := range rsList {
+		for _, owner := range rs.ObjectMeta.OwnerReferences {
+			if owner.UID == obj.ObjectMeta.UID {
+				if replicaset == nil || replicaset.ObjectMeta.CreationTimestamp.Time.Before(rs.ObjectMeta.CreationTimestamp.Time) {
+					replicaset = rs
+				}
+			}
+		}
+	}
+	return replicaset, nil
+}
+
+// podStatuses return why pods owned by owner are not ready, with node if withNode.
+func (ctl *Controller) podStatuses(ns string, owner types.UID, withNode bool) ([]notify.PodStatus, error) {
 	pods, err := ctl.podLister.Pods(ns).List(labels.Everything())
 	if err != nil {
-		return fmt.Errorf("list pods(%s): %w", ns, err)
+		return nil, fmt.Errorf("list pods(%s): %w", ns, err)
 	}
+
+	statuses := []notify.PodStatus{}
 	for _, pod := range pods {
 		isOwner := false
 		for _, ref := range pod.ObjectMeta.OwnerReferences {
@@ -103,7 +386,7 @@ func (ctl *Controller) Inspect(kind, key string) error {
 				break
 			}
 		}
-		if !isOwner || pod.Status.Phase == corev1.PodRunning {
+		if !isOwner || (pod.Status.Phase == corev1.PodRunning && podReady(pod)) {
 			continue
 		}
 		reason := pod.Status.Reason
@@ -119,14 +402,30 @@ func (ctl *Controller) Inspect(kind, key string) error {
 			}
 		}
 
-		msg = fmt.Sprintf("%s %s(%s)", msg, pod.Status.Phase, reason)
+		status := notify.PodStatus{
+			Name:   pod.Name,
+			Phase:  string(pod.Status.Phase),
+			Reason: reason,
+		}
+		if withNode {
+			status.Node = pod.Spec.NodeName
+		}
+		statuses = append(statuses, status)
 	}
+	return statuses, nil
+}
 
-	if err := ctl.notifyFunc(msg); err != nil {
-		log.Warn().Err(err).Msgf("notify %s", msg)
+func podReady(pod *corev1.Pod) bool {
+	for _, cstatus := range pod.Status.ContainerStatuses {
+		if !cstatus.Ready {
+			return false
+		}
 	}
+	return true
+}
 
-	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+func queueKey(kind, key string) string {
+	return fmt.Sprintf("%s;%s", kind, key)
 }
 
 var (
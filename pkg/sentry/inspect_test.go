@@ -0,0 +1,290 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestStatefulSetStatus(t *testing.T) {
+	newStatefulSet := func(strategy appsv1.StatefulSetUpdateStrategy, status appsv1.StatefulSetStatus) *appsv1.StatefulSet {
+		status.ObservedGeneration = 2
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 2},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3), UpdateStrategy: strategy},
+			Status:     status,
+		}
+	}
+	partitioned := appsv1.StatefulSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(2)},
+	}
+
+	fixtures := []struct {
+		name     string
+		obj      *appsv1.StatefulSet
+		complete bool
+	}{
+		{
+			"rolling updating",
+			newStatefulSet(
+				appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				appsv1.StatefulSetStatus{UpdatedReplicas: 3, ReadyReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			false,
+		},
+		{
+			"rolling updated",
+			newStatefulSet(
+				appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+				appsv1.StatefulSetStatus{UpdatedReplicas: 3, ReadyReplicas: 3, CurrentRevision: "db-2", UpdateRevision: "db-2"}),
+			true,
+		},
+		{
+			"partitioned updating",
+			newStatefulSet(partitioned, appsv1.StatefulSetStatus{UpdatedReplicas: 0, ReadyReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			false,
+		},
+		{
+			"partitioned updated",
+			newStatefulSet(partitioned, appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			true,
+		},
+		{
+			"ondelete waiting",
+			newStatefulSet(
+				appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+				appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2"}),
+			false,
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			status := statefulSetStatus(fixture.obj)
+			require.Equal(t, fixture.complete, status.complete)
+		})
+	}
+
+	status := statefulSetStatus(newStatefulSet(partitioned, appsv1.StatefulSetStatus{}))
+	require.Equal(t, int32(2), status.partition)
+}
+
+func TestDaemonSetStatus(t *testing.T) {
+	newDaemonSet := func(strategy appsv1.DaemonSetUpdateStrategyType, status appsv1.DaemonSetStatus) *appsv1.DaemonSet {
+		status.DesiredNumberScheduled = 3
+		if status.ObservedGeneration == 0 {
+			status.ObservedGeneration = 2
+		}
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Generation: 2},
+			Spec:       appsv1.DaemonSetSpec{UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: strategy}},
+			Status:     status,
+		}
+	}
+
+	fixtures := []struct {
+		name     string
+		obj      *appsv1.DaemonSet
+		complete bool
+	}{
+		{
+			"not observed",
+			newDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType,
+				appsv1.DaemonSetStatus{ObservedGeneration: 1, UpdatedNumberScheduled: 3, NumberAvailable: 3}),
+			false,
+		},
+		{
+			"rolling updating",
+			newDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType,
+				appsv1.DaemonSetStatus{UpdatedNumberScheduled: 2, NumberAvailable: 3}),
+			false,
+		},
+		{
+			"rolling unavailable",
+			newDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType,
+				appsv1.DaemonSetStatus{UpdatedNumberScheduled: 3, NumberAvailable: 3, NumberUnavailable: 1}),
+			false,
+		},
+		{
+			"rolling updated",
+			newDaemonSet(appsv1.RollingUpdateDaemonSetStrategyType,
+				appsv1.DaemonSetStatus{UpdatedNumberScheduled: 3, NumberAvailable: 3, NumberReady: 3}),
+			true,
+		},
+		{
+			"ondelete",
+			newDaemonSet(appsv1.OnDeleteDaemonSetStrategyType, appsv1.DaemonSetStatus{UpdatedNumberScheduled: 1}),
+			true,
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			status := daemonSetStatus(fixture.obj)
+			require.Equal(t, fixture.complete, status.complete)
+			require.Equal(t, int32(3), status.desired)
+		})
+	}
+}
+
+func TestCurrentRevision(t *testing.T) {
+	revision := func(name string, owner types.UID, n int64) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            name,
+				OwnerReferences: []metav1.OwnerReference{{UID: owner}},
+			},
+			Revision: n,
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := factory.Apps().V1().ControllerRevisions().Informer().GetIndexer()
+	require.NoError(t, indexer.Add(revision("agent-1", "agent", 1)))
+	require.NoError(t, indexer.Add(revision("agent-2", "agent", 2)))
+	require.NoError(t, indexer.Add(revision("other-3", "other", 3)))
+	ctl := &Controller{crLister: factory.Apps().V1().ControllerRevisions().Lister()}
+
+	current, err := ctl.currentRevision("default", "agent")
+	require.NoError(t, err)
+	require.Equal(t, "agent-2", current.Name)
+
+	current, err = ctl.currentRevision("default", "unknown")
+	require.NoError(t, err)
+	require.Nil(t, current)
+}
+
+func TestInspectDaemonSetStaleRevision(t *testing.T) {
+	daemonSet := func(generation int64, updated int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "agent", UID: "agent", Generation: generation},
+			Spec: appsv1.DaemonSetSpec{
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType},
+			},
+			Status: appsv1.DaemonSetStatus{
+				ObservedGeneration:     generation,
+				DesiredNumberScheduled: 3,
+				UpdatedNumberScheduled: updated,
+				NumberAvailable:        3,
+				NumberReady:            3,
+			},
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	dsIndexer := factory.Apps().V1().DaemonSets().Informer().GetIndexer()
+	// NOTE: created long before, e.g. reused by rollout undo
+	require.NoError(t, factory.Apps().V1().ControllerRevisions().Informer().GetIndexer().Add(&appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "agent-1",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+			OwnerReferences:   []metav1.OwnerReference{{UID: "agent"}},
+		},
+		Revision: 3,
+	}))
+
+	events := []notify.Event{}
+	options := newOptions()
+	ctl := &Controller{
+		Options:  options,
+		dsLister: factory.Apps().V1().DaemonSets().Lister(),
+		crLister: factory.Apps().V1().ControllerRevisions().Lister(),
+		rollouts: newRollouts(),
+	}
+	ctl.current.Store(&reloadable{Options: options, notifier: notify.NotifyFunc(func(e notify.Event) error {
+		events = append(events, e)
+		return nil
+	})})
+	inspect := func(ds *appsv1.DaemonSet) []notify.Action {
+		events = events[:0]
+		require.NoError(t, dsIndexer.Update(ds))
+		_ = ctl.inspectDaemonSet("DaemonSet", "default/agent", "default", "agent")
+		actions := []notify.Action{}
+		for _, e := range events {
+			actions = append(actions, e.Action)
+		}
+		return actions
+	}
+
+	require.Equal(t, []notify.Action{notify.RolloutStarted}, inspect(daemonSet(2, 0)))
+	require.Equal(t, []notify.Action{notify.RolloutCompleted}, inspect(daemonSet(2, 3)))
+	require.Equal(t, []notify.Action{notify.RolloutStarted}, inspect(daemonSet(3, 0)))
+}
+
+func TestInspectStatefulSetPartitionStep(t *testing.T) {
+	statefulSet := func(generation int64, partition, updated int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db", UID: "db", Generation: generation},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: int32Ptr(3),
+				UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+					Type:          appsv1.RollingUpdateStatefulSetStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{Partition: int32Ptr(partition)},
+				},
+			},
+			Status: appsv1.StatefulSetStatus{
+				ObservedGeneration: generation,
+				UpdatedReplicas:    updated,
+				ReadyReplicas:      3,
+				CurrentRevision:    "db-1",
+				UpdateRevision:     "db-2",
+			},
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	ssIndexer := factory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	// NOTE: created when template changed, kept by each partition step
+	require.NoError(t, factory.Apps().V1().ControllerRevisions().Informer().GetIndexer().Add(&appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "db-2",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Revision: 2,
+	}))
+
+	events := []notify.Event{}
+	options := newOptions()
+	ctl := &Controller{
+		Options:   options,
+		ssLister:  factory.Apps().V1().StatefulSets().Lister(),
+		crLister:  factory.Apps().V1().ControllerRevisions().Lister(),
+		podLister: factory.Core().V1().Pods().Lister(),
+		rollouts:  newRollouts(),
+	}
+	ctl.current.Store(&reloadable{Options: options, notifier: notify.NotifyFunc(func(e notify.Event) error {
+		events = append(events, e)
+		return nil
+	})})
+	inspect := func(ss *appsv1.StatefulSet) []notify.Action {
+		events = events[:0]
+		require.NoError(t, ssIndexer.Update(ss))
+		_ = ctl.inspectStatefulSet("StatefulSet", "default/db", "default", "db")
+		actions := []notify.Action{}
+		for _, e := range events {
+			actions = append(actions, e.Action)
+		}
+		return actions
+	}
+
+	require.Equal(t, []notify.Action{}, inspect(statefulSet(2, 2, 1)))
+	// lower partition
+	require.Equal(t, []notify.Action{notify.RolloutStarted, notify.RolloutProgressing}, inspect(statefulSet(3, 1, 1)))
+	require.Equal(t, []notify.Action{notify.RolloutCompleted}, inspect(statefulSet(3, 1, 2)))
+}
@@ -0,0 +1,147 @@
+package sentry
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/rs/zerolog/log"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// jobActions, Job run once, so it never progress.
+var jobActions = map[rolloutPhase]notify.Action{
+	rolloutStarted:   notify.JobStarted,
+	rolloutCompleted: notify.JobSucceeded,
+	rolloutFailed:    notify.JobFailed,
+}
+
+// inspectJob track run of Job as rollout, Job finished before first observed is ignored.
+func (ctl *Controller) inspectJob(kind, key, ns, name string) error {
+	obj, err := ctl.jobLister.Jobs(ns).Get(name)
+	if err != nil {
+		return fmt.Errorf("get job(%s): %w", key, err)
+	}
+
+	status := jobStatus(obj)
+	state, phases := ctl.rollouts.transit(queueKey(kind, key), obj.UID, status, time.Now())
+	for _, phase := range phases {
+		e := jobEvent(kind, obj, jobActions[phase])
+		if phase == rolloutFailed {
+			if e.Pods, err = ctl.podStatuses(ns, obj.UID, false); err != nil {
+				log.Warn().Err(err).Msgf("access pods of %s(%s)", kind, key)
+			}
+			e.Warnings = ctl.workloadWarnings(ns, state.startedAt, obj.UID)
+			ctl.attachLogs(ns, e.Pods)
+		}
+
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
+		}
+	}
+
+	if state.done() {
+		return nil
+	}
+	return fmt.Errorf("%s(%s): %w", kind, key, ErrNotReady)
+}
+
+// jobStatus map Job to rollout, complete once Job finished, either succeeded or failed.
+func jobStatus(obj *batchv1.Job) rolloutStatus {
+	status := rolloutStatus{
+		generation: obj.Generation,
+		desired:    jobCompletions(obj),
+		ready:      obj.Status.Succeeded,
+		available:  obj.Status.Succeeded,
+	}
+	if obj.Status.StartTime != nil {
+		status.startedAt = obj.Status.StartTime.Time
+	}
+
+	for _, cond := range obj.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			status.complete = true
+		case batchv1.JobFailed:
+			status.complete = true
+			status.failed = true
+			status.reason = cond.Reason
+			status.message = cond.Message
+		}
+	}
+	return status
+}
+
+func jobCompletions(obj *batchv1.Job) int32 {
+	if obj.Spec.Completions != nil {
+		return *obj.Spec.Completions
+	}
+	// NOTE: work queue, complete once any pod succeeded
+	return 1
+}
+
+func jobEvent(kind string, obj *batchv1.Job, action notify.Action) notify.Event {
+	now := time.Now()
+
+	backoffLimit := int32(6)
+	if obj.Spec.BackoffLimit != nil {
+		backoffLimit = *obj.Spec.BackoffLimit
+	}
+	job := &notify.Job{
+		Completions:  jobCompletions(obj),
+		Active:       obj.Status.Active,
+		Succeeded:    obj.Status.Succeeded,
+		Failed:       obj.Status.Failed,
+		BackoffLimit: backoffLimit,
+	}
+	if ref := metav1.GetControllerOf(obj); ref != nil && ref.Kind == "CronJob" {
+		job.CronJob = ref.Name
+	}
+	end := now
+	if obj.Status.CompletionTime != nil {
+		end = obj.Status.CompletionTime.Time
+	}
+	for _, cond := range obj.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			job.Reason = cond.Reason
+			job.Message = cond.Message
+			// NOTE: completionTime is only set when succeeded
+			end = cond.LastTransitionTime.Time
+		}
+	}
+	if start := obj.Status.StartTime; start != nil {
+		job.Duration = end.Sub(start.Time)
+	}
+
+	return notify.Event{
+		Kind:            kind,
+		Namespace:       obj.Namespace,
+		Name:            obj.Name,
+		UID:             string(obj.UID),
+		Labels:          obj.Labels,
+		Action:          action,
+		ResourceVersion: obj.ResourceVersion,
+		Timestamp:       now,
+		Desired:         job.Completions,
+		Ready:           job.Succeeded,
+		Job:             job,
+	}
+}
+
+// cronJob summary schedule of CronJob.
+func cronJob(obj *batchv1.CronJob) *notify.Job {
+	job := &notify.Job{Schedule: obj.Spec.Schedule}
+	if obj.Spec.Suspend != nil {
+		job.Suspend = *obj.Spec.Suspend
+	}
+	if obj.Status.LastScheduleTime != nil {
+		job.LastSchedule = obj.Status.LastScheduleTime.Time
+	}
+	return job
+}
@@ -0,0 +1,68 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobStatus(t *testing.T) {
+	start := metav1.NewTime(time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC))
+	job := func(conds ...batchv1.JobCondition) *batchv1.Job {
+		obj := &batchv1.Job{}
+		obj.Status.StartTime = &start
+		obj.Status.Conditions = conds
+		return obj
+	}
+
+	fixtures := []struct {
+		name     string
+		job      *batchv1.Job
+		complete bool
+		failed   bool
+	}{
+		{"running", job(), false, false},
+		{"succeeded", job(batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}), true, false},
+		{
+			"failed",
+			job(batchv1.JobCondition{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}),
+			true, true,
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			status := jobStatus(fixture.job)
+			require.Equal(t, fixture.complete, status.complete)
+			require.Equal(t, fixture.failed, status.failed)
+			require.Equal(t, start.Time, status.startedAt)
+		})
+	}
+}
+
+func TestJobEvent(t *testing.T) {
+	start := metav1.NewTime(time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC))
+	obj := &batchv1.Job{}
+	obj.Name = "report-1625126400"
+	obj.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: "report", Controller: func() *bool { b := true; return &b }()}}
+	obj.Status.StartTime = &start
+	obj.Status.Failed = 3
+	obj.Status.Conditions = []batchv1.JobCondition{{
+		Type:               batchv1.JobFailed,
+		Status:             corev1.ConditionTrue,
+		Reason:             "BackoffLimitExceeded",
+		LastTransitionTime: metav1.NewTime(start.Add(5 * time.Minute)),
+	}}
+
+	e := jobEvent("Job", obj, jobActions[rolloutFailed])
+	require.Equal(t, "report", e.Job.CronJob)
+	require.Equal(t, int32(1), e.Job.Completions)
+	require.Equal(t, int32(6), e.Job.BackoffLimit)
+	require.Equal(t, 5*time.Minute, e.Job.Duration)
+	require.Equal(t, "BackoffLimitExceeded", e.Job.Reason)
+}
@@ -0,0 +1,144 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/rs/zerolog/log"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// maxLogPods, fetch log of at most these pods for a notification.
+const maxLogPods = 3
+
+// LogTail fetch the tail of failing container log via pods/log,
+// which is attached to failure notification.
+type LogTail struct {
+	Pods corev1client.PodsGetter
+
+	// Lines, Bytes, fetch at most these lines and keep the last bytes of them
+	Lines int64
+	Bytes int64
+	// Timeout of each fetch
+	Timeout time.Duration
+}
+
+// Tail return the last lines of container log, of the previous terminated one if previous.
+func (t *LogTail) Tail(ns, pod, container string, previous bool) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.Timeout)
+	defer cancel()
+
+	// NOTE: limitBytes keep the first bytes, which drop the newest lines
+	opts := &corev1.PodLogOptions{Container: container, Previous: previous}
+	if t.Lines > 0 {
+		opts.TailLines = &t.Lines
+	}
+	stream, err := t.Pods.Pods(ns).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return "", fmt.Errorf("stream log of %s/%s[%s]: %w", ns, pod, container, err)
+	}
+	defer stream.Close()
+
+	if t.Bytes <= 0 {
+		b, err := ioutil.ReadAll(stream)
+		if err != nil {
+			return "", fmt.Errorf("read log of %s/%s[%s]: %w", ns, pod, container, err)
+		}
+		return string(b), nil
+	}
+	w := &tailWriter{n: int(t.Bytes)}
+	if _, err := io.Copy(w, stream); err != nil {
+		return "", fmt.Errorf("read log of %s/%s[%s]: %w", ns, pod, container, err)
+	}
+	return w.String(), nil
+}
+
+// tailWriter keep the last n bytes written.
+type tailWriter struct {
+	n         int
+	buf       []byte
+	truncated bool
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.n {
+		copy(w.buf, w.buf[len(w.buf)-w.n:])
+		w.buf = w.buf[:w.n]
+		w.truncated = true
+	}
+	return len(p), nil
+}
+
+// String return the kept bytes, without the partial first line if truncated.
+func (w *tailWriter) String() string {
+	b := w.buf
+	if i := bytes.IndexByte(b, '\n'); w.truncated && i >= 0 && i < len(b)-1 {
+		b = b[i+1:]
+	}
+	return string(b)
+}
+
+// logContainer return the failing container of pod which has log, prefer container if set,
+// previous if it is restarting.
+func logContainer(pod *corev1.Pod, container string) (string, bool, bool) {
+	for _, cstatus := range containerStatuses(pod) {
+		if container != "" && cstatus.Name != container {
+			continue
+		}
+		switch {
+		case cstatus.State.Terminated != nil && cstatus.State.Terminated.ExitCode != 0:
+			return cstatus.Name, false, true
+		case cstatus.LastTerminationState.Terminated != nil && !cstatus.Ready:
+			// NOTE: e.g. CrashLoopBackOff, the current one is waiting or just started
+			return cstatus.Name, true, true
+		case container != "" && cstatus.LastTerminationState.Terminated != nil:
+			return cstatus.Name, true, true
+		}
+	}
+	return "", false, false
+}
+
+// podLog return the redacted tail of failing container log, empty if disabled or unavailable.
+func (ctl *Controller) podLog(pod *corev1.Pod, container string) string {
+	if ctl.LogTail == nil {
+		return ""
+	}
+	name, previous, ok := logContainer(pod, container)
+	if !ok {
+		return ""
+	}
+	text, err := ctl.LogTail.Tail(pod.Namespace, pod.Name, name, previous)
+	if err != nil {
+		log.Warn().Err(err).Msgf("tail log")
+		return ""
+	}
+	return ctl.load().Redactor.RedactText(text)
+}
+
+// attachLogs fill Log of at most maxLogPods pods.
+func (ctl *Controller) attachLogs(ns string, pods []notify.PodStatus) {
+	if ctl.LogTail == nil {
+		return
+	}
+	n := 0
+	for i := range pods {
+		if n == maxLogPods {
+			return
+		}
+		pod, err := ctl.podLister.Pods(ns).Get(pods[i].Name)
+		if err != nil {
+			continue
+		}
+		if pods[i].Log = ctl.podLog(pod, ""); pods[i].Log != "" {
+			n++
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestLogContainer(t *testing.T) {
+	terminated := &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}
+	fixtures := []struct {
+		name      string
+		statuses  []corev1.ContainerStatus
+		container string
+		expected  string
+		previous  bool
+		ok        bool
+	}{
+		{
+			"crashloop",
+			[]corev1.ContainerStatus{
+				{Name: "sidecar", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+				{
+					Name:                 "app",
+					State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+					LastTerminationState: corev1.ContainerState{Terminated: terminated},
+				},
+			},
+			"", "app", true, true,
+		},
+		{
+			"terminated",
+			[]corev1.ContainerStatus{{Name: "app", State: corev1.ContainerState{Terminated: terminated}}},
+			"", "app", false, true,
+		},
+		{
+			"image pull",
+			[]corev1.ContainerStatus{{
+				Name:  "app",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}},
+			"", "", false, false,
+		},
+		{
+			"restarted and ready",
+			[]corev1.ContainerStatus{{
+				Name: "app", Ready: true, RestartCount: 5,
+				State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: corev1.ContainerState{Terminated: terminated},
+			}},
+			"app", "app", true, true,
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			pod.Status.ContainerStatuses = fixture.statuses
+			name, previous, ok := logContainer(pod, fixture.container)
+			require.Equal(t, fixture.expected, name)
+			require.Equal(t, fixture.previous, previous)
+			require.Equal(t, fixture.ok, ok)
+		})
+	}
+}
+
+func TestTailWriter(t *testing.T) {
+	w := &tailWriter{n: 20}
+	for _, line := range []string{"starting\n", "listening\n", "panic: nil map\n"} {
+		_, err := w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.Equal(t, "ning\npanic: nil map\n", string(w.buf))
+	require.Equal(t, "panic: nil map\n", w.String())
+
+	w = &tailWriter{n: 64}
+	_, err := w.Write([]byte("starting\npanic: nil map\n"))
+	require.NoError(t, err)
+	require.Equal(t, "starting\npanic: nil map\n", w.String())
+}
+
+func TestAttachLogs(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-1"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "app",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+	}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(pod))
+
+	options := newOptions()
+	ctl := &Controller{Options: options, podLister: corelisters.NewPodLister(indexer)}
+	ctl.current.Store(&reloadable{Options: options})
+
+	pods := []notify.PodStatus{{Name: "app-1"}, {Name: "app-2"}}
+	ctl.attachLogs("default", pods)
+	require.Empty(t, pods[0].Log)
+
+	ctl.LogTail = &LogTail{Pods: fake.NewSimpleClientset(pod).CoreV1(), Lines: 10, Bytes: 4, Timeout: time.Second}
+	ctl.attachLogs("default", pods)
+	// NOTE: the newest bytes are kept
+	require.Equal(t, "logs", pods[0].Log)
+	require.Empty(t, pods[1].Log)
+}
@@ -0,0 +1,169 @@
+package sentry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pathToken is a token of Change.Path, either field, or element of list keyed by merge key.
+type pathToken struct {
+	name string
+	elem bool
+}
+
+// parsePath split path like spec.template.spec.containers[app].image into tokens,
+// key in brackets may contain dot, e.g. hostAliases[10.0.0.1].
+func parsePath(path string) []pathToken {
+	tokens := []pathToken{}
+	name := strings.Builder{}
+	flush := func() {
+		if name.Len() > 0 {
+			tokens = append(tokens, pathToken{name: name.String()})
+			name.Reset()
+		}
+	}
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			flush()
+		case '[':
+			flush()
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				name.WriteString(path[i:])
+				i = len(path)
+				continue
+			}
+			tokens = append(tokens, pathToken{name: path[i+1 : i+end], elem: true})
+			i += end
+		default:
+			name.WriteByte(path[i])
+		}
+	}
+	flush()
+	return tokens
+}
+
+// ownedDepth return how many tokens are owned by fields of FieldsV1,
+// len(tokens) if the whole path, e.g. atomic list args is owned as a leaf.
+func ownedDepth(fields map[string]interface{}, tokens []pathToken) int {
+	if len(tokens) == 0 || len(fields) == 0 {
+		return len(tokens)
+	}
+
+	if tokens[0].elem {
+		for k, v := range fields {
+			child, ok := v.(map[string]interface{})
+			if !ok || !elemMatch(k, tokens[0].name) {
+				continue
+			}
+			return 1 + ownedDepth(child, tokens[1:])
+		}
+		return 0
+	}
+
+	// NOTE: key of map may contain dot, e.g. labels.app.kubernetes.io/name
+	depth := 0
+	names := []string{}
+	for i, token := range tokens {
+		if token.elem {
+			break
+		}
+		names = append(names, token.name)
+		child, ok := fields["f:"+strings.Join(names, ".")].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if d := i + 1 + ownedDepth(child, tokens[i+1:]); d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// elemMatch check whether key of FieldsV1 match element of list,
+// e.g. k:{"name":"app"} and v:"app" match app.
+func elemMatch(key, name string) bool {
+	switch {
+	case strings.HasPrefix(key, "k:"):
+		keys := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(key[2:]), &keys); err != nil {
+			return false
+		}
+		for _, v := range keys {
+			if fmt.Sprint(v) == name {
+				return true
+			}
+		}
+	case strings.HasPrefix(key, "v:"):
+		var v interface{}
+		if err := json.Unmarshal([]byte(key[2:]), &v); err != nil {
+			return false
+		}
+		return fmt.Sprint(v) == name
+	}
+	return false
+}
+
+// fieldOwner return entry of managedFields which own the deepest prefix of path,
+// the latest if multiple, and the depth of prefix.
+func fieldOwner(meta metav1.Object, path string) (*metav1.ManagedFieldsEntry, int) {
+	tokens := parsePath(path)
+
+	owner := (*metav1.ManagedFieldsEntry)(nil)
+	depth := 0
+	latest := time.Time{}
+	entries := meta.GetManagedFields()
+	for i := range entries {
+		entry := &entries[i]
+		if entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil || len(fields) == 0 {
+			continue
+		}
+		d := ownedDepth(fields, tokens)
+		if d == 0 || d < depth {
+			continue
+		}
+		if d > depth || entry.Time == nil || !entry.Time.Time.Before(latest) {
+			owner, depth = entry, d
+			latest = time.Time{}
+			if entry.Time != nil {
+				latest = entry.Time.Time
+			}
+		}
+	}
+	return owner, depth
+}
+
+// fieldManager return manager which own the field by managedFields, empty if unknown.
+func fieldManager(meta metav1.Object, path ...string) string {
+	joined := strings.Join(path, ".")
+	if owner, depth := fieldOwner(meta, joined); owner != nil && depth == len(parsePath(joined)) {
+		return owner.Manager
+	}
+	return ""
+}
+
+// changeManager return manager and operation which made the change,
+// the owner of its direct parent if the field is removed, e.g. env[DEBUG] by owner of env.
+func changeManager(meta metav1.Object, path string) (string, string) {
+	owner, depth := fieldOwner(meta, path)
+	n := len(parsePath(path))
+	switch {
+	case owner == nil:
+		return "", ""
+	case depth == n:
+	case depth == n-1 && depth >= 2:
+		// NOTE: removed, top-level parent like spec is shared by all managers
+	default:
+		return "", ""
+	}
+	return owner.Manager, string(owner.Operation)
+}
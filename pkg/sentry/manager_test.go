@@ -0,0 +1,76 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParsePath(t *testing.T) {
+	require.Equal(t, []pathToken{
+		{name: "spec"}, {name: "template"}, {name: "spec"},
+		{name: "containers"}, {name: "app", elem: true}, {name: "image"},
+	}, parsePath("spec.template.spec.containers[app].image"))
+	require.Equal(t, []pathToken{
+		{name: "spec"}, {name: "hostAliases"}, {name: "10.0.0.1", elem: true},
+	}, parsePath("spec.hostAliases[10.0.0.1]"))
+}
+
+func TestChangeManager(t *testing.T) {
+	before := metav1.NewTime(time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC))
+	after := metav1.NewTime(before.Add(time.Hour))
+	d := &appsv1.Deployment{}
+	d.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager: "argocd-controller", Operation: metav1.ManagedFieldsOperationApply, Time: &before,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{
+				"f:metadata":{"f:labels":{"f:app.kubernetes.io/name":{}}},
+				"f:spec":{"f:template":{"f:spec":{"f:containers":{
+					"k:{\"name\":\"app\"}":{".":{},"f:name":{},"f:image":{},"f:args":{},"f:env":{"k:{\"name\":\"LOG_LEVEL\"}":{".":{},"f:name":{},"f:value":{}}}}
+				}}}}
+			}`)},
+		},
+		{
+			Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, Time: &after,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{
+				"f:spec":{"f:template":{"f:spec":{"f:containers":{
+					"k:{\"name\":\"app\"}":{"f:resources":{"f:limits":{"f:memory":{}}}}
+				}}}}
+			}`)},
+		},
+		{
+			Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Time: &after,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:status":{"f:replicas":{}}}`)},
+		},
+	}
+
+	fixtures := []struct {
+		path      string
+		manager   string
+		operation string
+	}{
+		{"spec.template.spec.containers[app].image", "argocd-controller", "Apply"},
+		{"spec.template.spec.containers[app].args.1", "argocd-controller", "Apply"},
+		{"spec.template.spec.containers[app].env[LOG_LEVEL].value", "argocd-controller", "Apply"},
+		{"metadata.labels.app.kubernetes.io/name", "argocd-controller", "Apply"},
+		{"spec.template.spec.containers[app].resources.limits.memory", "kubectl-edit", "Update"},
+		// NOTE: removed, attributed to the owner of parent
+		{"spec.template.spec.containers[app].env[DEBUG]", "argocd-controller", "Apply"},
+		{"spec.paused", "", ""},
+		{"metadata.annotations.note", "", ""},
+	}
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.path, func(t *testing.T) {
+			manager, operation := changeManager(d, fixture.path)
+			require.Equal(t, fixture.manager, manager)
+			require.Equal(t, fixture.operation, operation)
+		})
+	}
+
+	require.Equal(t, "kubectl-edit", fieldManager(d, "spec", "template", "spec", "containers[app]", "resources"))
+	require.Equal(t, "", fieldManager(d, "spec", "paused"))
+}
@@ -0,0 +1,249 @@
+package sentry
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/rs/zerolog/log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// nodePressures are conditions of node which is abnormal when True.
+var nodePressures = []corev1.NodeConditionType{
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+}
+
+// conditionTaints are added by node controller according to conditions and unschedulable,
+// which are reported as NodeNotReady, NodePressure and NodeCordoned already.
+var conditionTaints = map[string]bool{
+	corev1.TaintNodeNotReady:           true,
+	corev1.TaintNodeUnreachable:        true,
+	corev1.TaintNodeUnschedulable:      true,
+	corev1.TaintNodeMemoryPressure:     true,
+	corev1.TaintNodeDiskPressure:       true,
+	corev1.TaintNodePIDPressure:        true,
+	corev1.TaintNodeNetworkUnavailable: true,
+}
+
+// nodeChange is a change of node found by comparing before and after.
+type nodeChange struct {
+	action  notify.Action
+	reason  string
+	message string
+	added   []string
+	removed []string
+}
+
+// nodeChanges return changes of readiness, pressures, unschedulable and taints.
+func nodeChanges(before, after *corev1.Node) []nodeChange {
+	changes := []nodeChange{}
+
+	bready, aready := nodeCondition(before, corev1.NodeReady), nodeCondition(after, corev1.NodeReady)
+	switch {
+	case conditionTrue(bready) && !conditionTrue(aready):
+		change := nodeChange{action: notify.NodeNotReady}
+		if aready != nil {
+			change.reason, change.message = aready.Reason, aready.Message
+		}
+		changes = append(changes, change)
+	case !conditionTrue(bready) && conditionTrue(aready):
+		changes = append(changes, nodeChange{action: notify.NodeReady, reason: aready.Reason, message: aready.Message})
+	}
+
+	for _, typ := range nodePressures {
+		bcond, acond := nodeCondition(before, typ), nodeCondition(after, typ)
+		switch {
+		case !conditionTrue(bcond) && conditionTrue(acond):
+			changes = append(changes, nodeChange{action: notify.NodePressure, reason: string(typ), message: acond.Message})
+		case conditionTrue(bcond) && !conditionTrue(acond):
+			change := nodeChange{action: notify.NodePressureRelieved, reason: string(typ)}
+			if acond != nil {
+				change.message = acond.Message
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	if !before.Spec.Unschedulable && after.Spec.Unschedulable {
+		changes = append(changes, nodeChange{action: notify.NodeCordoned})
+	} else if before.Spec.Unschedulable && !after.Spec.Unschedulable {
+		changes = append(changes, nodeChange{action: notify.NodeUncordoned})
+	}
+
+	btaints, ataints := nodeTaints(before), nodeTaints(after)
+	change := nodeChange{action: notify.NodeTainted}
+	for taint := range ataints {
+		if !btaints[taint] {
+			change.added = append(change.added, taint)
+		}
+	}
+	for taint := range btaints {
+		if !ataints[taint] {
+			change.removed = append(change.removed, taint)
+		}
+	}
+	if len(change.added) > 0 || len(change.removed) > 0 {
+		sort.Strings(change.added)
+		sort.Strings(change.removed)
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func nodeCondition(node *corev1.Node, typ corev1.NodeConditionType) *corev1.NodeCondition {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == typ {
+			return &node.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func conditionTrue(cond *corev1.NodeCondition) bool {
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
+// nodeTaints return taints as key=value:effect, except conditionTaints.
+func nodeTaints(node *corev1.Node) map[string]bool {
+	taints := map[string]bool{}
+	for _, taint := range node.Spec.Taints {
+		if conditionTaints[taint.Key] {
+			continue
+		}
+		taints[taint.ToString()] = true
+	}
+	return taints
+}
+
+// nodeConditions return abnormal conditions of node, e.g. NotReady and MemoryPressure.
+func nodeConditions(node *corev1.Node) []string {
+	conditions := []string{}
+	if !conditionTrue(nodeCondition(node, corev1.NodeReady)) {
+		conditions = append(conditions, "NotReady")
+	}
+	for _, typ := range nodePressures {
+		if conditionTrue(nodeCondition(node, typ)) {
+			conditions = append(conditions, string(typ))
+		}
+	}
+	return conditions
+}
+
+// nodeWorkloads return top-level owner of pods on node, e.g. DaemonSet/kube-system/fluentd.
+func (ctl *Controller) nodeWorkloads(name string) ([]string, error) {
+	pods, err := ctl.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	cur := ctl.load()
+	seen := map[string]bool{}
+	workloads := []string{}
+	for _, pod := range pods {
+		if pod.Spec.NodeName != name {
+			continue
+		}
+		if len(cur.IncludeNamespaces) > 0 && !cur.IncludeNamespaces[pod.Namespace] {
+			continue
+		}
+		owner := ctl.podOwner(pod)
+		workload := fmt.Sprintf("%s/%s/%s", owner.Kind, pod.Namespace, owner.Name)
+		if !seen[workload] {
+			seen[workload] = true
+			workloads = append(workloads, workload)
+		}
+	}
+	sort.Strings(workloads)
+	return workloads, nil
+}
+
+// nodeHandler report changes of node, which explain why workloads on it become unready.
+type nodeHandler struct {
+	ctl *Controller
+}
+
+var _ cache.ResourceEventHandler = (*nodeHandler)(nil)
+
+func (h *nodeHandler) OnAdd(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	if time.Since(node.CreationTimestamp.Time) > h.ctl.load().IgnoreCreatedBefore {
+		// NOTE: when restart
+		return
+	}
+	h.ctl.notifyNode(node, nodeChange{action: notify.NodeAdded})
+}
+
+func (h *nodeHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	h.ctl.notifyNode(node, nodeChange{action: notify.NodeRemoved})
+}
+
+func (h *nodeHandler) OnUpdate(before, after interface{}) {
+	bn, ok := before.(*corev1.Node)
+	if !ok {
+		return
+	}
+	an, ok := after.(*corev1.Node)
+	if !ok {
+		return
+	}
+	for _, change := range nodeChanges(bn, an) {
+		h.ctl.notifyNode(an, change)
+	}
+}
+
+// nodeRecovered, workloads are not listed for these actions.
+var nodeRecovered = map[notify.Action]bool{
+	notify.NodeAdded:            true,
+	notify.NodeReady:            true,
+	notify.NodePressureRelieved: true,
+	notify.NodeUncordoned:       true,
+}
+
+func (ctl *Controller) notifyNode(node *corev1.Node, change nodeChange) {
+	n := &notify.Node{
+		Conditions:    nodeConditions(node),
+		Unschedulable: node.Spec.Unschedulable,
+		Added:         change.added,
+		Removed:       change.removed,
+		Reason:        change.reason,
+		Message:       change.message,
+	}
+	if !nodeRecovered[change.action] {
+		var err error
+		if n.Workloads, err = ctl.nodeWorkloads(node.Name); err != nil {
+			log.Warn().Err(err).Msgf("access workloads of node %s", node.Name)
+		}
+	}
+
+	e := notify.Event{
+		Kind:            "Node",
+		Name:            node.Name,
+		UID:             string(node.UID),
+		Labels:          node.Labels,
+		Action:          change.action,
+		ResourceVersion: node.ResourceVersion,
+		Timestamp:       time.Now(),
+		Node:            n,
+	}
+	if err := ctl.load().notifier.Notify(e); err != nil {
+		log.Warn().Err(err).Msgf("notify Node(%s)", node.Name)
+	}
+}
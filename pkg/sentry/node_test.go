@@ -0,0 +1,91 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNodeChanges(t *testing.T) {
+	node := func(unschedulable bool, taints []corev1.Taint, conditions ...corev1.NodeCondition) *corev1.Node {
+		n := &corev1.Node{}
+		n.Spec.Unschedulable = unschedulable
+		n.Spec.Taints = taints
+		n.Status.Conditions = conditions
+		return n
+	}
+	cond := func(typ corev1.NodeConditionType, status corev1.ConditionStatus, reason string) corev1.NodeCondition {
+		return corev1.NodeCondition{Type: typ, Status: status, Reason: reason}
+	}
+	ready := cond(corev1.NodeReady, corev1.ConditionTrue, "KubeletReady")
+	unknown := cond(corev1.NodeReady, corev1.ConditionUnknown, "NodeStatusUnknown")
+	dedicated := corev1.Taint{Key: "dedicated", Value: "infra", Effect: corev1.TaintEffectNoSchedule}
+	unreachable := corev1.Taint{Key: corev1.TaintNodeUnreachable, Effect: corev1.TaintEffectNoExecute}
+
+	fixtures := []struct {
+		name     string
+		before   *corev1.Node
+		after    *corev1.Node
+		expected []nodeChange
+	}{
+		{"unchanged", node(false, nil, ready), node(false, nil, ready), []nodeChange{}},
+		{
+			"notready",
+			node(false, nil, ready), node(false, []corev1.Taint{unreachable}, unknown),
+			[]nodeChange{{action: notify.NodeNotReady, reason: "NodeStatusUnknown"}},
+		},
+		{
+			"ready",
+			node(false, nil, unknown), node(false, nil, ready),
+			[]nodeChange{{action: notify.NodeReady, reason: "KubeletReady"}},
+		},
+		{
+			"pressure",
+			node(false, nil, ready),
+			node(false, nil, ready, cond(corev1.NodeDiskPressure, corev1.ConditionTrue, "KubeletHasDiskPressure")),
+			[]nodeChange{{action: notify.NodePressure, reason: "DiskPressure"}},
+		},
+		{
+			"pressure relieved",
+			node(false, nil, ready, cond(corev1.NodeMemoryPressure, corev1.ConditionTrue, "KubeletHasInsufficientMemory")),
+			node(false, nil, ready, cond(corev1.NodeMemoryPressure, corev1.ConditionFalse, "KubeletHasSufficientMemory")),
+			[]nodeChange{{action: notify.NodePressureRelieved, reason: "MemoryPressure"}},
+		},
+		{
+			"cordon",
+			node(false, nil, ready),
+			node(true, []corev1.Taint{{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}}, ready),
+			[]nodeChange{{action: notify.NodeCordoned}},
+		},
+		{"uncordon", node(true, nil, ready), node(false, nil, ready), []nodeChange{{action: notify.NodeUncordoned}}},
+		{
+			"taint",
+			node(false, nil, ready), node(false, []corev1.Taint{dedicated}, ready),
+			[]nodeChange{{action: notify.NodeTainted, added: []string{"dedicated=infra:NoSchedule"}}},
+		},
+		{
+			"untaint",
+			node(false, []corev1.Taint{dedicated}, ready), node(false, nil, ready),
+			[]nodeChange{{action: notify.NodeTainted, removed: []string{"dedicated=infra:NoSchedule"}}},
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			require.Equal(t, fixture.expected, nodeChanges(fixture.before, fixture.after))
+		})
+	}
+}
+
+func TestNodeConditions(t *testing.T) {
+	n := &corev1.Node{}
+	n.Status.Conditions = []corev1.NodeCondition{
+		{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+		{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+	}
+	require.Equal(t, []string{"NotReady", "MemoryPressure"}, nodeConditions(n))
+}
@@ -2,29 +2,49 @@ package sentry
 
 import (
 	"regexp"
+	"text/template"
 	"time"
 
+	"github.com/j2gg0s/kubenotify/pkg/diff"
 	"k8s.io/client-go/tools/cache"
 )
 
 type Options struct {
 	KeyFunc func(interface{}) (string, error)
 
-	TimeFormat string
-
 	InitBackoff time.Duration
 	MaxBackoff  time.Duration
 	MaxRetries  int
 
 	IgnoreCreatedBefore time.Duration
 
+	// RolloutDeadline, mark rollout failed if not complete after,
+	// only for workload without progress deadline, e.g. DaemonSet and StatefulSet
+	RolloutDeadline time.Duration
+
 	Excludes []*regexp.Regexp
 	Includes []*regexp.Regexp
 
+	// Redactor replace sensitive value with fingerprint before diff, see diff.Redactor
+	Redactor *diff.Redactor
+
+	// RestartThreshold, report pod once restarts of container reach, 0 to disable
+	RestartThreshold int32
+
+	// LogTail, attach tail of failing container log to failure notification, disabled if nil
+	LogTail *LogTail
+
+	// IgnoreScaling, do not notify replicas change of workload
+	IgnoreScaling bool
+
+	// ImageLink render link of image change, see ImageLink
+	ImageLink *template.Template
+
 	// Namespaces, watch only these namespaces, default all
 	IncludeNamespaces map[string]bool
-	// Resources, watch only these resources, default all
-	// Support Deployment, StatefulSet, DaemonSet
+	// Resources, watch only these resources, default DefaultResources
+	// Support Deployment, StatefulSet, DaemonSet, CronJob, Job, ConfigMap, Secret, HorizontalPodAutoscaler,
+	// Pod for health of pods, e.g. CrashLoopBackOff, and Node for conditions, cordon and taints
 	IncludeResources map[string]bool
 
 	Debug          bool
@@ -35,8 +55,7 @@ type Option func(*Options)
 
 func newOptions() *Options {
 	return &Options{
-		KeyFunc:    cache.DeletionHandlingMetaNamespaceKeyFunc,
-		TimeFormat: "15:04:05Z07:00",
+		KeyFunc: cache.DeletionHandlingMetaNamespaceKeyFunc,
 
 		// 1s, 2s, 4s, 8s, 16s, 32s, 1m4s, 2m8s, 4m16s, 8m32s
 		InitBackoff: time.Second,
@@ -45,10 +64,42 @@ func newOptions() *Options {
 
 		IgnoreCreatedBefore: time.Minute,
 
+		// same as default progressDeadlineSeconds of Deployment
+		RolloutDeadline: time.Minute * 10,
+
+		RestartThreshold: 5,
+
+		Redactor: defaultRedactor,
+
 		EnableRevision: true,
 	}
 }
 
+var defaultRedactor = func() *diff.Redactor {
+	r, err := diff.NewRedactor(diff.DefaultRedactPaths, diff.DefaultRedactFlags)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}()
+
+// DefaultResources are watched if IncludeResources is empty,
+// others require more permissions and are noisy in large cluster, so they are opt-in.
+var DefaultResources = []string{"Deployment", "StatefulSet", "DaemonSet"}
+
+// watches return whether resource is watched, DefaultResources if IncludeResources is empty.
+func (o *Options) watches(resource string) bool {
+	if len(o.IncludeResources) > 0 {
+		return o.IncludeResources[resource]
+	}
+	for _, r := range DefaultResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
 func IncludeResources(resources ...string) Option {
 	return func(o *Options) {
 		if o.IncludeResources == nil {
@@ -79,12 +130,6 @@ func WithKeyFunc(kf func(interface{}) (string, error)) Option {
 	}
 }
 
-func WithTimeFormat(tf string) Option {
-	return func(o *Options) {
-		o.TimeFormat = tf
-	}
-}
-
 func WithExcludes(excludes []*regexp.Regexp) Option {
 	return func(o *Options) {
 		o.Excludes = excludes
@@ -114,3 +159,39 @@ func DisableRevision() Option {
 		o.EnableRevision = false
 	}
 }
+
+func WithRolloutDeadline(d time.Duration) Option {
+	return func(o *Options) {
+		o.RolloutDeadline = d
+	}
+}
+
+func WithImageLink(link *template.Template) Option {
+	return func(o *Options) {
+		o.ImageLink = link
+	}
+}
+
+func WithRedactor(r *diff.Redactor) Option {
+	return func(o *Options) {
+		o.Redactor = r
+	}
+}
+
+func IgnoreScaling() Option {
+	return func(o *Options) {
+		o.IgnoreScaling = true
+	}
+}
+
+func WithRestartThreshold(n int32) Option {
+	return func(o *Options) {
+		o.RestartThreshold = n
+	}
+}
+
+func WithLogTail(t *LogTail) Option {
+	return func(o *Options) {
+		o.LogTail = t
+	}
+}
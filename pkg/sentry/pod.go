@@ -0,0 +1,346 @@
+package sentry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/rs/zerolog/log"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// podAlertCooldown, report the same problem of a container at most once in,
+// e.g. container flap between CrashLoopBackOff and Running.
+const podAlertCooldown = time.Hour
+
+// imagePullReasons are waiting reasons of container which can not pull image.
+var imagePullReasons = map[string]bool{
+	"ImagePullBackOff": true,
+	"ErrImagePull":     true,
+	"InvalidImageName": true,
+}
+
+// rollingKinds are workloads whose rollout is tracked, problems of pod during rollout
+// are reported as RolloutFailed instead.
+var rollingKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// podProblem is a problem of pod found by comparing before and after.
+type podProblem struct {
+	action    notify.Action
+	container string
+	reason    string
+	message   string
+	restarts  int32
+}
+
+// podProblems return problems which appear in after but not in before,
+// restarts is reported once restart count reach threshold, 0 to disable.
+// At most one problem is reported for each container,
+// by the order OOMKilled, CrashLoopBackOff, ImagePullBackOff and restarts.
+func podProblems(before, after *corev1.Pod, threshold int32) []podProblem {
+	problems := []podProblem{}
+	if after.Status.Reason == "Evicted" && before.Status.Reason != "Evicted" {
+		problems = append(problems, podProblem{
+			action:  notify.PodEvicted,
+			reason:  after.Status.Reason,
+			message: after.Status.Message,
+		})
+	}
+
+	prevs := map[string]corev1.ContainerStatus{}
+	for _, cstatus := range containerStatuses(before) {
+		prevs[cstatus.Name] = cstatus
+	}
+	for _, cstatus := range containerStatuses(after) {
+		prev := prevs[cstatus.Name]
+		problem := podProblem{container: cstatus.Name, restarts: cstatus.RestartCount}
+		waiting, prevWaiting := waitingReason(cstatus), waitingReason(prev)
+
+		switch {
+		case oomKilled(prev, cstatus):
+			problem.action, problem.reason = notify.PodOOMKilled, "OOMKilled"
+			if terminated := lastTerminated(cstatus); terminated != nil {
+				problem.message = fmt.Sprintf("exit code %d", terminated.ExitCode)
+			}
+		case waiting == "CrashLoopBackOff" && prevWaiting != "CrashLoopBackOff":
+			problem.action, problem.reason = notify.PodCrashLooping, waiting
+			problem.message = cstatus.State.Waiting.Message
+		case imagePullReasons[waiting] && !imagePullReasons[prevWaiting]:
+			problem.action, problem.reason = notify.PodImagePullBackOff, waiting
+			problem.message = cstatus.State.Waiting.Message
+		case threshold > 0 && prev.RestartCount < threshold && cstatus.RestartCount >= threshold:
+			problem.action, problem.reason = notify.PodRestarting, "Restarted"
+			if terminated := lastTerminated(cstatus); terminated != nil {
+				problem.reason = terminated.Reason
+				problem.message = fmt.Sprintf("exit code %d", terminated.ExitCode)
+			}
+		default:
+			continue
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func containerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	return append(
+		append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+}
+
+func waitingReason(cstatus corev1.ContainerStatus) string {
+	if cstatus.State.Waiting == nil {
+		return ""
+	}
+	return cstatus.State.Waiting.Reason
+}
+
+// lastTerminated return the current state if terminated, or the last.
+func lastTerminated(cstatus corev1.ContainerStatus) *corev1.ContainerStateTerminated {
+	if cstatus.State.Terminated != nil {
+		return cstatus.State.Terminated
+	}
+	return cstatus.LastTerminationState.Terminated
+}
+
+// oomKilled check whether container is killed by OOM since before,
+// either restarted or terminated.
+func oomKilled(before, after corev1.ContainerStatus) bool {
+	if after.RestartCount > before.RestartCount {
+		terminated := after.LastTerminationState.Terminated
+		return terminated != nil && terminated.Reason == "OOMKilled"
+	}
+	terminated := after.State.Terminated
+	return terminated != nil && terminated.Reason == "OOMKilled" && before.State.Terminated == nil
+}
+
+// podAlerts record when problem of container reported, keyed by uid of pod,
+// and problems found by handler but not reported by worker yet, keyed by queue key of pod.
+type podAlerts struct {
+	mu      sync.Mutex
+	sent    map[types.UID]map[string]time.Time
+	pending map[string][]podProblem
+}
+
+func newPodAlerts() *podAlerts {
+	return &podAlerts{
+		sent:    map[types.UID]map[string]time.Time{},
+		pending: map[string][]podProblem{},
+	}
+}
+
+func (a *podAlerts) forget(uid types.UID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.sent, uid)
+}
+
+// add problems to report by worker.
+func (a *podAlerts) add(key string, problems []podProblem) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending[key] = append(a.pending[key], problems...)
+}
+
+// take return and remove pending problems.
+func (a *podAlerts) take(key string) []podProblem {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	problems := a.pending[key]
+	delete(a.pending, key)
+	return problems
+}
+
+// allow return whether problem should be reported, at most once in podAlertCooldown.
+func (a *podAlerts) allow(uid types.UID, problem podProblem, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := fmt.Sprintf("%s/%s", problem.container, problem.action)
+	sent, ok := a.sent[uid]
+	if !ok {
+		sent = map[string]time.Time{}
+		a.sent[uid] = sent
+	}
+	if last, ok := sent[key]; ok && now.Sub(last) < podAlertCooldown {
+		return false
+	}
+	sent[key] = now
+	return true
+}
+
+// podOwner return the top-level owner of pod, e.g. Deployment via ReplicaSet,
+// or the pod itself if not controlled.
+func (ctl *Controller) podOwner(pod *corev1.Pod) metav1.OwnerReference {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return metav1.OwnerReference{Kind: "Pod", Name: pod.Name, UID: pod.UID}
+	}
+	if ref.Kind != "ReplicaSet" {
+		return *ref
+	}
+
+	rs, err := ctl.rsLister.ReplicaSets(pod.Namespace).Get(ref.Name)
+	if err != nil {
+		log.Debug().Err(err).Msgf("get replicaset(%s/%s) of pod %s", pod.Namespace, ref.Name, pod.Name)
+		return *ref
+	}
+	if dref := metav1.GetControllerOf(rs); dref != nil && dref.Kind == "Deployment" {
+		return *dref
+	}
+	return *ref
+}
+
+// ownerLabels return labels of owner returned by podOwner, so that event of pod is routed as owner,
+// nil if owner is not found or not watched.
+func (ctl *Controller) ownerLabels(pod *corev1.Pod, owner metav1.OwnerReference) map[string]string {
+	var (
+		obj metav1.Object
+		err error
+	)
+	switch {
+	case owner.Kind == "Pod":
+		return pod.Labels
+	case owner.Kind == "Deployment":
+		obj, err = ctl.dLister.Deployments(pod.Namespace).Get(owner.Name)
+	case owner.Kind == "ReplicaSet":
+		obj, err = ctl.rsLister.ReplicaSets(pod.Namespace).Get(owner.Name)
+	case owner.Kind == "StatefulSet":
+		obj, err = ctl.ssLister.StatefulSets(pod.Namespace).Get(owner.Name)
+	case owner.Kind == "DaemonSet":
+		obj, err = ctl.dsLister.DaemonSets(pod.Namespace).Get(owner.Name)
+	case owner.Kind == "Job" && ctl.jobLister != nil:
+		obj, err = ctl.jobLister.Jobs(pod.Namespace).Get(owner.Name)
+	default:
+		return nil
+	}
+	if err != nil {
+		log.Debug().Err(err).Msgf("get %s(%s/%s) of pod %s", owner.Kind, pod.Namespace, owner.Name, pod.Name)
+		return nil
+	}
+	return obj.GetLabels()
+}
+
+// podHandler report pod health, independent of rollout.
+type podHandler struct {
+	ctl *Controller
+}
+
+var _ cache.ResourceEventHandler = (*podHandler)(nil)
+
+// OnAdd ignore pods, problems are found by comparing with before.
+func (h *podHandler) OnAdd(obj interface{}) {}
+
+func (h *podHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pod, ok := obj.(*corev1.Pod); ok {
+		h.ctl.podAlerts.forget(pod.UID)
+	}
+}
+
+func (h *podHandler) OnUpdate(before, after interface{}) {
+	bp, ok := before.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	ap, ok := after.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	h.ctl.onPodChange(bp, ap)
+}
+
+// onPodChange find problems of pod and enqueue them,
+// which are reported by worker since fetching log and notifying may be slow.
+func (ctl *Controller) onPodChange(before, after *corev1.Pod) {
+	cur := ctl.load()
+	if len(cur.IncludeNamespaces) > 0 && !cur.IncludeNamespaces[after.Namespace] {
+		return
+	}
+
+	problems := podProblems(before, after, ctl.RestartThreshold)
+	if len(problems) == 0 {
+		return
+	}
+
+	key, err := ctl.KeyFunc(after)
+	if err != nil {
+		log.Warn().Err(err).Msgf("access key of pod %s/%s", after.Namespace, after.Name)
+		return
+	}
+	ctl.podAlerts.add(queueKey("Pod", key), problems)
+	ctl.queue.Add(queueKey("Pod", key))
+}
+
+// inspectPod report pending problems of pod, see onPodChange.
+func (ctl *Controller) inspectPod(kind, key, ns, name string) error {
+	problems := ctl.podAlerts.take(queueKey(kind, key))
+	if len(problems) == 0 {
+		return nil
+	}
+	after, err := ctl.podLister.Pods(ns).Get(name)
+	if err != nil {
+		log.Debug().Err(err).Msgf("ignore pod %s: not found", key)
+		return nil
+	}
+
+	cur := ctl.load()
+	owner := ctl.podOwner(after)
+	ownerKey := fmt.Sprintf("%s/%s", after.Namespace, owner.Name)
+	if rollingKinds[owner.Kind] && ctl.rollouts.active(queueKey(owner.Kind, ownerKey)) {
+		log.Debug().Msgf("ignore pod %s/%s: %s(%s) is rolling", after.Namespace, after.Name, owner.Kind, ownerKey)
+		return nil
+	}
+
+	now := time.Now()
+	for _, problem := range problems {
+		if !ctl.podAlerts.allow(after.UID, problem, now) {
+			continue
+		}
+
+		reason := problem.reason
+		if problem.container != "" {
+			reason = fmt.Sprintf("%s[%s]", problem.container, problem.reason)
+		}
+		e := notify.Event{
+			Kind:            owner.Kind,
+			Namespace:       after.Namespace,
+			Name:            owner.Name,
+			UID:             string(owner.UID),
+			Labels:          ctl.ownerLabels(after, owner),
+			Action:          problem.action,
+			ResourceVersion: after.ResourceVersion,
+			Timestamp:       now,
+			Pods: []notify.PodStatus{{
+				Name:     after.Name,
+				Phase:    string(after.Status.Phase),
+				Reason:   reason,
+				Node:     after.Spec.NodeName,
+				Restarts: problem.restarts,
+				Message:  problem.message,
+			}},
+		}
+		if problem.action != notify.PodRestarting {
+			// NOTE: e.g. probe failures and volume mount errors
+			e.Warnings = ctl.warnings(after.CreationTimestamp.Time, after.UID)
+		}
+		if problem.container != "" {
+			e.Pods[0].Log = ctl.podLog(after, problem.container)
+		}
+		if err := cur.notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", owner.Kind, ownerKey)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestPodProblems(t *testing.T) {
+	pod := func(statuses ...corev1.ContainerStatus) *corev1.Pod {
+		p := &corev1.Pod{}
+		p.Status.Phase = corev1.PodRunning
+		p.Status.ContainerStatuses = statuses
+		return p
+	}
+	running := corev1.ContainerStatus{
+		Name:  "app",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}
+	waiting := func(reason string, restarts int32) corev1.ContainerStatus {
+		return corev1.ContainerStatus{
+			Name:         "app",
+			RestartCount: restarts,
+			State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+		}
+	}
+	oom := running
+	oom.RestartCount = 1
+	oom.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}
+	evicted := pod()
+	evicted.Status.Phase = corev1.PodFailed
+	evicted.Status.Reason = "Evicted"
+	evicted.Status.Message = "The node was low on resource: memory."
+
+	fixtures := []struct {
+		name     string
+		before   *corev1.Pod
+		after    *corev1.Pod
+		expected []podProblem
+	}{
+		{"healthy", pod(running), pod(running), []podProblem{}},
+		{
+			"crashloop",
+			pod(running), pod(waiting("CrashLoopBackOff", 2)),
+			[]podProblem{{action: notify.PodCrashLooping, container: "app", reason: "CrashLoopBackOff", restarts: 2}},
+		},
+		{"still crashloop", pod(waiting("CrashLoopBackOff", 2)), pod(waiting("CrashLoopBackOff", 3)), []podProblem{}},
+		{
+			"oomkilled",
+			pod(running), pod(oom),
+			[]podProblem{{action: notify.PodOOMKilled, container: "app", reason: "OOMKilled", message: "exit code 137", restarts: 1}},
+		},
+		{
+			"image pull",
+			pod(waiting("ContainerCreating", 0)), pod(waiting("ErrImagePull", 0)),
+			[]podProblem{{action: notify.PodImagePullBackOff, container: "app", reason: "ErrImagePull"}},
+		},
+		{"image pull backoff", pod(waiting("ErrImagePull", 0)), pod(waiting("ImagePullBackOff", 0)), []podProblem{}},
+		{
+			"restarts",
+			pod(waiting("Error", 4)), pod(waiting("Error", 5)),
+			[]podProblem{{action: notify.PodRestarting, container: "app", reason: "Restarted", restarts: 5}},
+		},
+		{
+			"evicted",
+			pod(running), evicted,
+			[]podProblem{{action: notify.PodEvicted, reason: "Evicted", message: "The node was low on resource: memory."}},
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			require.Equal(t, fixture.expected, podProblems(fixture.before, fixture.after, 5))
+		})
+	}
+}
+
+func TestPodAlerts(t *testing.T) {
+	a := newPodAlerts()
+	now := time.Now()
+	crash := podProblem{action: notify.PodCrashLooping, container: "app"}
+
+	require.True(t, a.allow("uid", crash, now))
+	require.False(t, a.allow("uid", crash, now.Add(time.Minute)))
+	require.True(t, a.allow("uid", podProblem{action: notify.PodOOMKilled, container: "app"}, now))
+	require.True(t, a.allow("uid", crash, now.Add(podAlertCooldown)))
+
+	a.forget("uid")
+	require.True(t, a.allow("uid", crash, now.Add(podAlertCooldown)))
+}
+
+func TestOwnerLabels(t *testing.T) {
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	require.NoError(t, factory.Apps().V1().Deployments().Informer().GetIndexer().Add(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "d", Labels: map[string]string{"team": "payments"}},
+	}))
+	require.NoError(t, factory.Apps().V1().ReplicaSets().Informer().GetIndexer().Add(&appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "app-5d4f",
+			UID:             "rs",
+			Labels:          map[string]string{"pod-template-hash": "5d4f"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "app", UID: "d", Controller: boolPtr(true)}},
+		},
+	}))
+	ctl := &Controller{
+		dLister:  factory.Apps().V1().Deployments().Lister(),
+		rsLister: factory.Apps().V1().ReplicaSets().Lister(),
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            "app-5d4f-x9z",
+		Labels:          map[string]string{"pod-template-hash": "5d4f"},
+		OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-5d4f", UID: "rs", Controller: boolPtr(true)}},
+	}}
+	owner := ctl.podOwner(pod)
+	require.Equal(t, "Deployment", owner.Kind)
+	require.Equal(t, map[string]string{"team": "payments"}, ctl.ownerLabels(pod, owner))
+
+	// not controlled
+	pod.OwnerReferences = nil
+	require.Equal(t, pod.Labels, ctl.ownerLabels(pod, ctl.podOwner(pod)))
+}
+
+func TestInspectPod(t *testing.T) {
+	before := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", UID: "pod"}}
+	before.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app"}}
+	after := before.DeepCopy()
+	after.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	require.NoError(t, factory.Core().V1().Pods().Informer().GetIndexer().Add(after))
+
+	events := []notify.Event{}
+	options := newOptions()
+	ctl := &Controller{
+		Options:   options,
+		podLister: factory.Core().V1().Pods().Lister(),
+		rollouts:  newRollouts(),
+		podAlerts: newPodAlerts(),
+		queue:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	ctl.current.Store(&reloadable{Options: options, notifier: notify.NotifyFunc(func(e notify.Event) error {
+		events = append(events, e)
+		return nil
+	})})
+
+	// NOTE: handler only enqueue, worker report
+	ctl.onPodChange(before, after)
+	require.Empty(t, events)
+	require.Equal(t, 1, ctl.queue.Len())
+
+	require.NoError(t, ctl.inspectPod("Pod", "default/app", "default", "app"))
+	require.Len(t, events, 1)
+	require.Equal(t, notify.PodCrashLooping, events[0].Action)
+	require.Equal(t, "app[CrashLoopBackOff]", events[0].Pods[0].Reason)
+
+	// taken already
+	require.NoError(t, ctl.inspectPod("Pod", "default/app", "default", "app"))
+	require.Len(t, events, 1)
+}
@@ -0,0 +1,130 @@
+package sentry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// RevisionAnnotation is set on deployment and replicaset by deployment controller.
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// RevisionHistoryAnnotation record revisions of replicaset before bumped by deployment controller.
+const RevisionHistoryAnnotation = "deployment.kubernetes.io/revision-history"
+
+// detectRollback return rollback if the new template match an older replicaset,
+// e.g. `kubectl rollout undo`, rollback of argo or helm, nil if not.
+// The replicaset is older only if its revision, before bumped by deployment controller,
+// is lower than the revision of deployment, which exclude the replicaset of a forward rollout.
+func (ctl *Controller) detectRollback(before, after *appsv1.Deployment) (*notify.Rollback, error) {
+	if apiequality.Semantic.DeepEqual(before.Spec.Template, after.Spec.Template) {
+		return nil, nil
+	}
+
+	rsList, err := ctl.rsLister.ReplicaSets(after.Namespace).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list replicaset(%s): %w", after.Namespace, err)
+	}
+
+	var from, to *appsv1.ReplicaSet
+	for _, rs := range rsList {
+		if !ownedBy(rs, after) {
+			continue
+		}
+		if from == nil && equalIgnoreHash(rs.Spec.Template, before.Spec.Template) {
+			from = rs
+		}
+		if to == nil && equalIgnoreHash(rs.Spec.Template, after.Spec.Template) {
+			to = rs
+		}
+	}
+	if to == nil {
+		return nil, nil
+	}
+
+	current, ok := parseRevision(before.Annotations[RevisionAnnotation])
+	if !ok {
+		return nil, nil
+	}
+	revision, ok := originalRevision(to, current)
+	if !ok {
+		return nil, nil
+	}
+
+	rollback := &notify.Rollback{
+		ToRevision: strconv.FormatInt(revision, 10),
+		FromImages: images(before.Spec.Template.Spec),
+		ToImages:   images(after.Spec.Template.Spec),
+	}
+	if from != nil {
+		rollback.FromRevision = from.Annotations[RevisionAnnotation]
+	} else {
+		rollback.FromRevision = before.Annotations[RevisionAnnotation]
+	}
+
+	return rollback, nil
+}
+
+// originalRevision return the latest revision of replicaset lower than current,
+// either the revision or one of revision history if bumped already.
+func originalRevision(rs *appsv1.ReplicaSet, current int64) (int64, bool) {
+	revisions := []string{rs.Annotations[RevisionAnnotation]}
+	if history := rs.Annotations[RevisionHistoryAnnotation]; history != "" {
+		revisions = append(revisions, strings.Split(history, ",")...)
+	}
+
+	var original int64
+	found := false
+	for _, s := range revisions {
+		revision, ok := parseRevision(s)
+		if !ok || revision >= current {
+			continue
+		}
+		if !found || revision > original {
+			original, found = revision, true
+		}
+	}
+	return original, found
+}
+
+func parseRevision(s string) (int64, bool) {
+	revision, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return revision, true
+}
+
+func ownedBy(rs *appsv1.ReplicaSet, obj *appsv1.Deployment) bool {
+	for _, owner := range rs.ObjectMeta.OwnerReferences {
+		if owner.UID == obj.ObjectMeta.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// equalIgnoreHash compare template of replicaset and deployment,
+// ignore pod-template-hash which is added to replicaset.
+func equalIgnoreHash(rsTemplate, template corev1.PodTemplateSpec) bool {
+	t1, t2 := rsTemplate.DeepCopy(), template.DeepCopy()
+	delete(t1.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	delete(t2.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return apiequality.Semantic.DeepEqual(t1, t2)
+}
+
+// images return container=image of each container.
+func images(spec corev1.PodSpec) []string {
+	images := make([]string, 0, len(spec.Containers))
+	for _, container := range spec.Containers {
+		images = append(images, fmt.Sprintf("%s=%s", container.Name, container.Image))
+	}
+	return images
+}
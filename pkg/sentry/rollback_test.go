@@ -0,0 +1,87 @@
+package sentry
+
+import (
+	"testing"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectRollback(t *testing.T) {
+	template := func(image string) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "app"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+		}
+	}
+	deployment := func(revision, image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "app",
+				UID:         "uid",
+				Annotations: map[string]string{RevisionAnnotation: revision},
+			},
+			Spec: appsv1.DeploymentSpec{Template: template(image)},
+		}
+	}
+	replicaset := func(name, revision, history, image string) *appsv1.ReplicaSet {
+		t := template(image)
+		t.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+		annotations := map[string]string{RevisionAnnotation: revision}
+		if history != "" {
+			annotations[RevisionHistoryAnnotation] = history
+		}
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "default",
+				Name:            "app-" + name,
+				Annotations:     annotations,
+				OwnerReferences: []metav1.OwnerReference{{UID: "uid"}},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: t},
+		}
+	}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := factory.Apps().V1().ReplicaSets().Informer().GetIndexer()
+	require.NoError(t, indexer.Add(replicaset("a", "1", "", "app:v1")))
+	require.NoError(t, indexer.Add(replicaset("b", "2", "", "app:v2")))
+	ctl := &Controller{rsLister: factory.Apps().V1().ReplicaSets().Lister()}
+
+	rollback, err := ctl.detectRollback(deployment("2", "app:v2"), deployment("2", "app:v1"))
+	require.NoError(t, err)
+	require.Equal(t, &notify.Rollback{
+		FromRevision: "2",
+		ToRevision:   "1",
+		FromImages:   []string{"app=app:v2"},
+		ToImages:     []string{"app=app:v1"},
+	}, rollback)
+
+	// new template
+	rollback, err = ctl.detectRollback(deployment("2", "app:v2"), deployment("2", "app:v3"))
+	require.NoError(t, err)
+	require.Nil(t, rollback)
+
+	// forward rollout, the new replicaset is created already
+	require.NoError(t, indexer.Add(replicaset("c", "3", "", "app:v3")))
+	rollback, err = ctl.detectRollback(deployment("2", "app:v2"), deployment("2", "app:v3"))
+	require.NoError(t, err)
+	require.Nil(t, rollback)
+
+	// rollback, the older replicaset is bumped already
+	require.NoError(t, indexer.Update(replicaset("a", "4", "1", "app:v1")))
+	rollback, err = ctl.detectRollback(deployment("3", "app:v3"), deployment("3", "app:v1"))
+	require.NoError(t, err)
+	require.Equal(t, &notify.Rollback{
+		FromRevision: "3",
+		ToRevision:   "1",
+		FromImages:   []string{"app=app:v3"},
+		ToImages:     []string{"app=app:v1"},
+	}, rollback)
+}
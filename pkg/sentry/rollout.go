@@ -0,0 +1,173 @@
+package sentry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type rolloutPhase int
+
+const (
+	rolloutStarted rolloutPhase = iota + 1
+	rolloutProgressing
+	rolloutCompleted
+	rolloutFailed
+)
+
+var rolloutActions = map[rolloutPhase]notify.Action{
+	rolloutStarted:     notify.RolloutStarted,
+	rolloutProgressing: notify.RolloutProgressing,
+	rolloutCompleted:   notify.RolloutCompleted,
+	rolloutFailed:      notify.RolloutFailed,
+}
+
+// rolloutStatus is the observed status of workload.
+type rolloutStatus struct {
+	generation int64
+
+	desired   int32
+	updated   int32
+	ready     int32
+	available int32
+
+	// strategy, partition and revision, only for StatefulSet
+	strategy        string
+	partition       int32
+	currentRevision string
+	updateRevision  string
+	// blocking, pods block the rollout
+	blocking []string
+
+	complete bool
+	failed   bool
+	reason   string
+	message  string
+
+	// startedAt, e.g. created at of revision, default first observed,
+	// used only if after the previous generation is last observed,
+	// since revision may be reused by rollback or not cached yet.
+	startedAt time.Time
+	// deadline, mark failed if not complete after started,
+	// for workload without progress deadline, e.g. DaemonSet
+	deadline time.Duration
+}
+
+type rolloutState struct {
+	uid        types.UID
+	generation int64
+	startedAt  time.Time
+	// seenAt, the last time the generation is observed
+	seenAt time.Time
+	// revision, update revision of StatefulSet
+	revision string
+	phase    rolloutPhase
+}
+
+// rollouts track rollout of workloads, keyed by kind;namespace/name,
+// each phase is entered at most once per generation.
+type rollouts struct {
+	mu     sync.Mutex
+	states map[string]*rolloutState
+}
+
+func newRollouts() *rollouts {
+	return &rollouts{states: map[string]*rolloutState{}}
+}
+
+func (r *rollouts) forget(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.states, key)
+}
+
+// active means rollout is started and not done.
+func (r *rollouts) active(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.states[key]
+	return state != nil && !state.done()
+}
+
+// done means rollout is observed and no more phase to enter.
+func (r *rollouts) done(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.states[key]
+	return state != nil && state.done()
+}
+
+// skip mark generation as completed without rollout, e.g. scaled,
+// unless the rollout of previous generation is not done.
+func (r *rollouts) skip(key string, uid types.UID, generation int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.states[key]
+	if state != nil && state.uid == uid && !state.done() {
+		return
+	}
+	now := time.Now()
+	r.states[key] = &rolloutState{uid: uid, generation: generation, startedAt: now, seenAt: now, phase: rolloutCompleted}
+}
+
+// transit move rollout to the observed status, return phases entered.
+func (r *rollouts) transit(key string, uid types.UID, status rolloutStatus, now time.Time) (rolloutState, []rolloutPhase) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	phases := []rolloutPhase{}
+
+	prev := r.states[key]
+	state := prev
+	if state == nil || state.uid != uid || state.generation != status.generation {
+		state = &rolloutState{
+			uid:        uid,
+			generation: status.generation,
+			startedAt:  now,
+			seenAt:     now,
+			revision:   status.updateRevision,
+		}
+		// NOTE: revision of the previous generation may be reused, e.g. rollout undo, lowered partition,
+		// or be the newest cached, e.g. changed minReadySeconds or new revision not cached yet.
+		if prev != nil && prev.uid == uid && status.startedAt.After(prev.seenAt) &&
+			(status.updateRevision == "" || status.updateRevision != prev.revision) {
+			state.startedAt = status.startedAt
+		}
+		r.states[key] = state
+		if status.complete {
+			// NOTE: nothing to rollout, e.g. changed metadata only
+			state.phase = rolloutCompleted
+			return *state, phases
+		}
+		state.phase = rolloutStarted
+		phases = append(phases, rolloutStarted)
+	}
+	state.seenAt = now
+
+	if !status.complete && !status.failed &&
+		status.deadline > 0 && now.Sub(state.startedAt) > status.deadline {
+		status.failed = true
+	}
+
+	switch {
+	case state.phase == rolloutCompleted || state.phase == rolloutFailed:
+	case status.failed:
+		state.phase = rolloutFailed
+		phases = append(phases, rolloutFailed)
+	case status.complete:
+		state.phase = rolloutCompleted
+		phases = append(phases, rolloutCompleted)
+	case state.phase == rolloutStarted && status.updated > 0:
+		state.phase = rolloutProgressing
+		phases = append(phases, rolloutProgressing)
+	}
+
+	return *state, phases
+}
+
+// done means no more phase to enter.
+func (s rolloutState) done() bool {
+	return s.phase == rolloutCompleted || s.phase == rolloutFailed
+}
@@ -0,0 +1,76 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRolloutsTransit(t *testing.T) {
+	r := newRollouts()
+	now := time.Now()
+	key := "Deployment;default/app"
+
+	steps := []struct {
+		status   rolloutStatus
+		expected []rolloutPhase
+	}{
+		{rolloutStatus{generation: 2, desired: 3}, []rolloutPhase{rolloutStarted}},
+		{rolloutStatus{generation: 2, desired: 3}, []rolloutPhase{}},
+		{rolloutStatus{generation: 2, desired: 3, updated: 1}, []rolloutPhase{rolloutProgressing}},
+		{rolloutStatus{generation: 2, desired: 3, updated: 2}, []rolloutPhase{}},
+		{rolloutStatus{generation: 2, desired: 3, updated: 3, complete: true}, []rolloutPhase{rolloutCompleted}},
+		{rolloutStatus{generation: 2, desired: 3, updated: 3, complete: true}, []rolloutPhase{}},
+		// next generation failed directly
+		{rolloutStatus{generation: 3, desired: 3, failed: true}, []rolloutPhase{rolloutStarted, rolloutFailed}},
+		{rolloutStatus{generation: 3, desired: 3, failed: true}, []rolloutPhase{}},
+		// already complete when first observed
+		{rolloutStatus{generation: 4, desired: 3, updated: 3, complete: true}, []rolloutPhase{}},
+	}
+
+	for i, step := range steps {
+		_, phases := r.transit(key, "uid", step.status, now.Add(time.Duration(i)*time.Second))
+		require.Equal(t, step.expected, phases, "step %d", i)
+	}
+
+	state, _ := r.transit(key, "uid", rolloutStatus{generation: 4, complete: true}, now)
+	require.True(t, state.done())
+
+	r.forget(key)
+	_, phases := r.transit(key, "uid", rolloutStatus{generation: 4}, now)
+	require.Equal(t, []rolloutPhase{rolloutStarted}, phases)
+}
+
+func TestRolloutsDeadline(t *testing.T) {
+	r := newRollouts()
+	now := time.Now()
+	key := "DaemonSet;default/agent"
+
+	// NOTE: revision may be older than the generation, start from first observed
+	status := rolloutStatus{generation: 1, desired: 3, startedAt: now.Add(-time.Hour), deadline: 2 * time.Minute}
+	state, phases := r.transit(key, "uid", status, now)
+	require.Equal(t, []rolloutPhase{rolloutStarted}, phases)
+	require.Equal(t, now, state.startedAt)
+
+	_, phases = r.transit(key, "uid", status, now.Add(time.Minute))
+	require.Equal(t, []rolloutPhase{}, phases)
+
+	_, phases = r.transit(key, "uid", status, now.Add(3*time.Minute))
+	require.Equal(t, []rolloutPhase{rolloutFailed}, phases)
+
+	// stale revision, e.g. rollout undo
+	status.generation = 2
+	state, phases = r.transit(key, "uid", status, now.Add(time.Hour))
+	require.Equal(t, []rolloutPhase{rolloutStarted}, phases)
+	require.Equal(t, now.Add(time.Hour), state.startedAt)
+
+	// new revision created after the previous generation is observed
+	status.generation, status.startedAt = 3, now.Add(2*time.Hour-time.Minute)
+	state, phases = r.transit(key, "uid", status, now.Add(2*time.Hour))
+	require.Equal(t, []rolloutPhase{rolloutStarted}, phases)
+	require.Equal(t, status.startedAt, state.startedAt)
+
+	_, phases = r.transit(key, "uid", status, now.Add(2*time.Hour+time.Minute+time.Second))
+	require.Equal(t, []rolloutPhase{rolloutFailed}, phases)
+}
@@ -0,0 +1,245 @@
+package sentry
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/rs/zerolog/log"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// autoscalerManager is the field manager of HorizontalPodAutoscaler when it scale workload.
+const autoscalerManager = "kube-controller-manager"
+
+// scalingEvent classify replicas change of workload as scaled by autoscaler or manually,
+// by the field manager of spec.replicas if known,
+// otherwise by whether desired replicas of the HorizontalPodAutoscaler target the workload is the new replicas.
+func (ctl *Controller) scalingEvent(kind string, meta metav1.Object, from, to int32) notify.Event {
+	scaling := &notify.Scaling{From: from, To: to, Manual: true}
+
+	manager := fieldManager(meta, "spec", "replicas")
+	scaling.By = manager
+
+	hpa, err := ctl.autoscalerOf(kind, meta.GetNamespace(), meta.GetName())
+	if err != nil {
+		log.Warn().Err(err).Msgf("access autoscaler of %s(%s/%s)", kind, meta.GetNamespace(), meta.GetName())
+	}
+	switch {
+	case manager == autoscalerManager:
+		scaling.Manual = false
+	case manager == "" && hpa != nil && hpa.Status.DesiredReplicas == to:
+		scaling.Manual = false
+	}
+	if hpa != nil && !scaling.Manual {
+		scaling.By = fmt.Sprintf("HorizontalPodAutoscaler/%s", hpa.Name)
+		scaling.Min, scaling.Max = autoscalerRange(hpa)
+		scaling.Metrics = autoscalerMetrics(hpa)
+	}
+
+	return notify.Event{
+		Kind:            kind,
+		Namespace:       meta.GetNamespace(),
+		Name:            meta.GetName(),
+		UID:             string(meta.GetUID()),
+		Labels:          meta.GetLabels(),
+		Action:          notify.Scaled,
+		ResourceVersion: meta.GetResourceVersion(),
+		Timestamp:       time.Now(),
+		Desired:         to,
+		Scaling:         scaling,
+	}
+}
+
+// autoscalerOf return the HorizontalPodAutoscaler target the workload,
+// nil if not found or HorizontalPodAutoscaler is not watched.
+func (ctl *Controller) autoscalerOf(kind, ns, name string) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	if ctl.hpaLister == nil {
+		return nil, nil
+	}
+	hpas, err := ctl.hpaLister.HorizontalPodAutoscalers(ns).List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("list autoscalers(%s): %w", ns, err)
+	}
+	for _, hpa := range hpas {
+		if hpa.Spec.ScaleTargetRef.Kind == kind && hpa.Spec.ScaleTargetRef.Name == name {
+			return hpa, nil
+		}
+	}
+	return nil, nil
+}
+
+func autoscalerRange(hpa *autoscalingv2.HorizontalPodAutoscaler) (int32, int32) {
+	min := int32(1)
+	if hpa.Spec.MinReplicas != nil {
+		min = *hpa.Spec.MinReplicas
+	}
+	return min, hpa.Spec.MaxReplicas
+}
+
+// autoscalerMetrics render each metric as current/target, e.g. cpu 85%/70%.
+func autoscalerMetrics(hpa *autoscalingv2.HorizontalPodAutoscaler) []string {
+	metrics := []string{}
+	for _, spec := range hpa.Spec.Metrics {
+		name, target := metricTarget(spec)
+		current := "<unknown>"
+		for _, status := range hpa.Status.CurrentMetrics {
+			if sname, value, ok := metricCurrent(status); ok && status.Type == spec.Type && sname == name {
+				current = metricValue(value.AverageUtilization, value.AverageValue, value.Value)
+			}
+		}
+		metrics = append(metrics, fmt.Sprintf(
+			"%s %s/%s", name, current,
+			metricValue(target.AverageUtilization, target.AverageValue, target.Value)))
+	}
+	return metrics
+}
+
+func metricTarget(spec autoscalingv2.MetricSpec) (string, autoscalingv2.MetricTarget) {
+	switch {
+	case spec.Resource != nil:
+		return string(spec.Resource.Name), spec.Resource.Target
+	case spec.ContainerResource != nil:
+		return fmt.Sprintf("%s[%s]", spec.ContainerResource.Name, spec.ContainerResource.Container), spec.ContainerResource.Target
+	case spec.Pods != nil:
+		return spec.Pods.Metric.Name, spec.Pods.Target
+	case spec.Object != nil:
+		return spec.Object.Metric.Name, spec.Object.Target
+	case spec.External != nil:
+		return spec.External.Metric.Name, spec.External.Target
+	}
+	return string(spec.Type), autoscalingv2.MetricTarget{}
+}
+
+func metricCurrent(status autoscalingv2.MetricStatus) (string, autoscalingv2.MetricValueStatus, bool) {
+	switch {
+	case status.Resource != nil:
+		return string(status.Resource.Name), status.Resource.Current, true
+	case status.ContainerResource != nil:
+		return fmt.Sprintf("%s[%s]", status.ContainerResource.Name, status.ContainerResource.Container), status.ContainerResource.Current, true
+	case status.Pods != nil:
+		return status.Pods.Metric.Name, status.Pods.Current, true
+	case status.Object != nil:
+		return status.Object.Metric.Name, status.Object.Current, true
+	case status.External != nil:
+		return status.External.Metric.Name, status.External.Current, true
+	}
+	return "", autoscalingv2.MetricValueStatus{}, false
+}
+
+func metricValue(utilization *int32, quantities ...*resource.Quantity) string {
+	if utilization != nil {
+		return fmt.Sprintf("%d%%", *utilization)
+	}
+	for _, q := range quantities {
+		if q != nil {
+			return q.String()
+		}
+	}
+	return "<unknown>"
+}
+
+// autoscalerState is the degraded state of HorizontalPodAutoscaler reported last time.
+type autoscalerState struct {
+	pinned   bool
+	inactive bool
+}
+
+// autoscalers track degraded state of HorizontalPodAutoscaler, keyed by kind;namespace/name.
+type autoscalers struct {
+	mu     sync.Mutex
+	states map[string]autoscalerState
+}
+
+func newAutoscalers() *autoscalers {
+	return &autoscalers{states: map[string]autoscalerState{}}
+}
+
+func (a *autoscalers) forget(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.states, key)
+}
+
+// transit return actions of state change, the first observed state is reported only if report.
+func (a *autoscalers) transit(key string, state autoscalerState, report bool) []notify.Action {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prev, ok := a.states[key]
+	a.states[key] = state
+	if !ok && !report {
+		return nil
+	}
+
+	actions := []notify.Action{}
+	if state.pinned && !prev.pinned {
+		actions = append(actions, notify.AutoscalerPinned)
+	}
+	if state.inactive && !prev.inactive {
+		actions = append(actions, notify.AutoscalerInactive)
+	}
+	if (prev.pinned || prev.inactive) && !state.pinned && !state.inactive {
+		actions = append(actions, notify.AutoscalerRecovered)
+	}
+	return actions
+}
+
+// inspectAutoscaler report HorizontalPodAutoscaler pinned at maxReplicas or unable to scale,
+// e.g. failed to fetch metrics.
+func (ctl *Controller) inspectAutoscaler(kind, key, ns, name string) error {
+	hpa, err := ctl.hpaLister.HorizontalPodAutoscalers(ns).Get(name)
+	if err != nil {
+		return fmt.Errorf("get autoscaler(%s): %w", key, err)
+	}
+
+	scaling := &notify.Scaling{
+		From:    hpa.Status.CurrentReplicas,
+		To:      hpa.Status.DesiredReplicas,
+		By:      fmt.Sprintf("%s/%s", hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name),
+		Metrics: autoscalerMetrics(hpa),
+	}
+	scaling.Min, scaling.Max = autoscalerRange(hpa)
+
+	state := autoscalerState{pinned: hpa.Status.CurrentReplicas >= hpa.Spec.MaxReplicas}
+	for _, cond := range hpa.Status.Conditions {
+		switch {
+		case cond.Type == autoscalingv2.ScalingActive && cond.Status == corev1.ConditionFalse:
+			state.inactive = true
+			scaling.Reason, scaling.Message = cond.Reason, cond.Message
+		case cond.Type == autoscalingv2.ScalingLimited && cond.Status == corev1.ConditionTrue &&
+			cond.Reason == "TooManyReplicas":
+			state.pinned = true
+			if scaling.Reason == "" {
+				scaling.Reason, scaling.Message = cond.Reason, cond.Message
+			}
+		}
+	}
+
+	// NOTE: report degraded autoscaler when created, but not when restart
+	report := time.Since(hpa.CreationTimestamp.Time) < ctl.load().IgnoreCreatedBefore
+	for _, action := range ctl.autoscalers.transit(queueKey(kind, key), state, report) {
+		e := notify.Event{
+			Kind:            kind,
+			Namespace:       hpa.Namespace,
+			Name:            hpa.Name,
+			UID:             string(hpa.UID),
+			Labels:          hpa.Labels,
+			Action:          action,
+			ResourceVersion: hpa.ResourceVersion,
+			Timestamp:       time.Now(),
+			Desired:         hpa.Status.DesiredReplicas,
+			Scaling:         scaling,
+		}
+		if err := ctl.load().notifier.Notify(e); err != nil {
+			log.Warn().Err(err).Msgf("notify %s(%s)", kind, key)
+		}
+	}
+	return nil
+}
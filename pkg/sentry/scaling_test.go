@@ -0,0 +1,154 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFieldManager(t *testing.T) {
+	ts := metav1.NewTime(time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC))
+	d := &appsv1.Deployment{}
+	d.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager: "kubectl-client-side-apply", Operation: metav1.ManagedFieldsOperationUpdate, Time: &ts,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:template":{}}}`)},
+		},
+		{
+			Manager: "kube-controller-manager", Operation: metav1.ManagedFieldsOperationUpdate, Time: &ts,
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+		},
+	}
+
+	require.Equal(t, "kube-controller-manager", fieldManager(d, "spec", "replicas"))
+	require.Equal(t, "kubectl-client-side-apply", fieldManager(d, "spec", "template"))
+	require.Equal(t, "", fieldManager(d, "spec", "paused"))
+}
+
+func TestAutoscalerMetrics(t *testing.T) {
+	utilization := func(n int32) *int32 { return &n }
+	rps := resource.MustParse("100")
+	currentRPS := resource.MustParse("150")
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	hpa.Spec.Metrics = []autoscalingv2.MetricSpec{
+		{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name:   corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: utilization(70)},
+			},
+		},
+		{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricSource{
+				Metric: autoscalingv2.MetricIdentifier{Name: "http_requests"},
+				Target: autoscalingv2.MetricTarget{Type: autoscalingv2.AverageValueMetricType, AverageValue: &rps},
+			},
+		},
+	}
+	hpa.Status.CurrentMetrics = []autoscalingv2.MetricStatus{
+		{
+			Type: autoscalingv2.PodsMetricSourceType,
+			Pods: &autoscalingv2.PodsMetricStatus{
+				Metric:  autoscalingv2.MetricIdentifier{Name: "http_requests"},
+				Current: autoscalingv2.MetricValueStatus{AverageValue: &currentRPS},
+			},
+		},
+	}
+
+	require.Equal(t, []string{"cpu <unknown>/70%", "http_requests 150/100"}, autoscalerMetrics(hpa))
+}
+
+func TestAutoscalersTransit(t *testing.T) {
+	a := newAutoscalers()
+	key := "HorizontalPodAutoscaler;default/app"
+
+	// first observed when restart
+	require.Empty(t, a.transit(key, autoscalerState{pinned: true}, false))
+	require.Empty(t, a.transit(key, autoscalerState{pinned: true}, true))
+	require.Equal(t,
+		[]notify.Action{notify.AutoscalerInactive},
+		a.transit(key, autoscalerState{pinned: true, inactive: true}, true))
+	require.Equal(t,
+		[]notify.Action{notify.AutoscalerRecovered},
+		a.transit(key, autoscalerState{}, true))
+
+	// first observed when created
+	a.forget(key)
+	require.Equal(t, []notify.Action{notify.AutoscalerInactive}, a.transit(key, autoscalerState{inactive: true}, true))
+}
+
+func TestRolloutsSkip(t *testing.T) {
+	r := newRollouts()
+	now := time.Now()
+	key := "Deployment;default/app"
+
+	r.skip(key, "uid", 2)
+	_, phases := r.transit(key, "uid", rolloutStatus{generation: 2, desired: 3}, now)
+	require.Empty(t, phases)
+
+	// NOTE: scaled during rollout
+	_, phases = r.transit(key, "uid", rolloutStatus{generation: 3, desired: 3}, now)
+	require.Equal(t, []rolloutPhase{rolloutStarted}, phases)
+	r.skip(key, "uid", 4)
+	require.True(t, r.active(key))
+}
+
+func TestScalingEvent(t *testing.T) {
+	deployment := func(manager string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"}}
+		if manager != "" {
+			d.ManagedFields = []metav1.ManagedFieldsEntry{{
+				Manager:   manager,
+				Operation: metav1.ManagedFieldsOperationUpdate,
+				FieldsV1:  &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:replicas":{}}}`)},
+			}}
+		}
+		return d
+	}
+
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := factory.Autoscaling().V2beta2().HorizontalPodAutoscalers().Informer().GetIndexer()
+	require.NoError(t, indexer.Add(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "app"},
+			MaxReplicas:    10,
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{DesiredReplicas: 4},
+	}))
+	ctl := &Controller{hpaLister: factory.Autoscaling().V2beta2().HorizontalPodAutoscalers().Lister()}
+
+	fixtures := []struct {
+		name    string
+		manager string
+		to      int32
+		manual  bool
+		by      string
+	}{
+		{"autoscaler", autoscalerManager, 4, false, "HorizontalPodAutoscaler/app"},
+		{"manual to desired replicas", "kubectl", 4, true, "kubectl"},
+		{"manual", "kubectl", 6, true, "kubectl"},
+		{"unknown manager", "", 4, false, "HorizontalPodAutoscaler/app"},
+		{"unknown manager not desired", "", 6, true, ""},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.name, func(t *testing.T) {
+			e := ctl.scalingEvent("Deployment", deployment(fixture.manager), 2, fixture.to)
+			require.Equal(t, fixture.manual, e.Scaling.Manual)
+			require.Equal(t, fixture.by, e.Scaling.By)
+		})
+	}
+}
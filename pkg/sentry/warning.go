@@ -0,0 +1,145 @@
+package sentry
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+// eventIndex index Event by uid of involved object.
+const eventIndex = "involvedObject.uid"
+
+// maxWarnings, at most these warnings are attached to notification.
+const maxWarnings = 5
+
+func indexEventByObject(obj interface{}) ([]string, error) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil, nil
+	}
+	return []string{string(event.InvolvedObject.UID)}, nil
+}
+
+// eventTime return when event is seen last.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// eventCount return how many times event occurs.
+func eventCount(event *corev1.Event) int32 {
+	switch {
+	case event.Series != nil && event.Series.Count > 0:
+		return event.Series.Count
+	case event.Count > 0:
+		return event.Count
+	}
+	return 1
+}
+
+// warnings return warning events of objects seen after since,
+// deduplicated by reason with counts, the most frequent first.
+func (ctl *Controller) warnings(since time.Time, uids ...types.UID) []notify.Warning {
+	if ctl.eventIndexer == nil {
+		return nil
+	}
+
+	events := []*corev1.Event{}
+	for _, uid := range uids {
+		objs, err := ctl.eventIndexer.ByIndex(eventIndex, string(uid))
+		if err != nil {
+			continue
+		}
+		for _, obj := range objs {
+			if event, ok := obj.(*corev1.Event); ok {
+				events = append(events, event)
+			}
+		}
+	}
+	return aggregateWarnings(events, since)
+}
+
+func aggregateWarnings(events []*corev1.Event, since time.Time) []notify.Warning {
+	latest := map[string]time.Time{}
+	byReason := map[string]*notify.Warning{}
+	reasons := []string{}
+	for _, event := range events {
+		if event.Type != corev1.EventTypeWarning {
+			continue
+		}
+		seen := eventTime(event)
+		if seen.Before(since) {
+			continue
+		}
+
+		w, ok := byReason[event.Reason]
+		if !ok {
+			w = &notify.Warning{Reason: event.Reason}
+			byReason[event.Reason] = w
+			reasons = append(reasons, event.Reason)
+		}
+		w.Count += eventCount(event)
+		if !seen.Before(latest[event.Reason]) {
+			latest[event.Reason] = seen
+			w.Message = event.Message
+			w.Object = fmt.Sprintf("%s/%s", event.InvolvedObject.Kind, event.InvolvedObject.Name)
+		}
+	}
+
+	sort.Slice(reasons, func(i, j int) bool {
+		wi, wj := byReason[reasons[i]], byReason[reasons[j]]
+		if wi.Count != wj.Count {
+			return wi.Count > wj.Count
+		}
+		return wi.Reason < wj.Reason
+	})
+	warnings := []notify.Warning{}
+	for _, reason := range reasons {
+		if len(warnings) == maxWarnings {
+			break
+		}
+		warnings = append(warnings, *byReason[reason])
+	}
+	return warnings
+}
+
+// workloadWarnings return warnings of owners, e.g. Deployment and its ReplicaSet,
+// and pods owned by them.
+func (ctl *Controller) workloadWarnings(ns string, since time.Time, owners ...types.UID) []notify.Warning {
+	uids := append([]types.UID{}, owners...)
+	pods, err := ctl.podLister.Pods(ns).List(labels.Everything())
+	if err == nil {
+		for _, pod := range pods {
+			for _, ref := range pod.OwnerReferences {
+				for _, owner := range owners {
+					if ref.UID == owner {
+						uids = append(uids, pod.UID)
+					}
+				}
+			}
+		}
+	}
+	return ctl.warnings(since, uids...)
+}
+
+// addEventIndexer index warning events for lookup by involved object.
+func addEventIndexer(informer cache.SharedIndexInformer) (cache.Indexer, error) {
+	if err := informer.AddIndexers(cache.Indexers{eventIndex: indexEventByObject}); err != nil {
+		return nil, fmt.Errorf("add indexer %s: %w", eventIndex, err)
+	}
+	return informer.GetIndexer(), nil
+}
@@ -0,0 +1,49 @@
+package sentry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/j2gg0s/kubenotify/pkg/notify"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWarnings(t *testing.T) {
+	now := time.Date(2021, 7, 1, 8, 0, 0, 0, time.UTC)
+	event := func(name string, typ string, uid types.UID, pod, reason, message string, count int32, seen time.Time) *corev1.Event {
+		e := &corev1.Event{
+			Type:           typ,
+			Reason:         reason,
+			Message:        message,
+			Count:          count,
+			LastTimestamp:  metav1.NewTime(seen),
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod, UID: uid},
+		}
+		e.Name, e.Namespace = name, "default"
+		return e
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{eventIndex: indexEventByObject})
+	for _, e := range []*corev1.Event{
+		event("e1", corev1.EventTypeWarning, "pod-1", "app-1", "FailedScheduling", "0/3 nodes are available", 3, now.Add(-time.Minute)),
+		event("e2", corev1.EventTypeWarning, "pod-2", "app-2", "FailedScheduling", "0/3 nodes are available: 3 Insufficient cpu", 2, now),
+		event("e3", corev1.EventTypeWarning, "pod-1", "app-1", "Unhealthy", "Readiness probe failed", 1, now),
+		event("e4", corev1.EventTypeNormal, "pod-1", "app-1", "Scheduled", "assigned to node-1", 1, now),
+		event("e5", corev1.EventTypeWarning, "pod-1", "app-1", "BackOff", "Back-off restarting", 9, now.Add(-time.Hour)),
+		event("e6", corev1.EventTypeWarning, "pod-3", "other-1", "FailedMount", "secret not found", 1, now),
+	} {
+		require.NoError(t, indexer.Add(e))
+	}
+
+	ctl := &Controller{eventIndexer: indexer}
+	require.Equal(t, []notify.Warning{
+		{Reason: "FailedScheduling", Message: "0/3 nodes are available: 3 Insufficient cpu", Count: 5, Object: "Pod/app-2"},
+		{Reason: "Unhealthy", Message: "Readiness probe failed", Count: 1, Object: "Pod/app-1"},
+	}, ctl.warnings(now.Add(-10*time.Minute), "pod-1", "pod-2"))
+
+	require.Empty(t, ctl.warnings(now, "pod-4"))
+}
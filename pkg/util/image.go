@@ -0,0 +1,59 @@
+package util
+
+import "strings"
+
+// Image is the parsed reference of container image,
+// e.g. registry.example.com:5000/team/app:v1@sha256:xxx.
+type Image struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+const DefaultRegistry = "docker.io"
+
+func ParseImage(s string) Image {
+	img := Image{}
+
+	if i := strings.Index(s, "@"); i >= 0 {
+		s, img.Digest = s[:i], s[i+1:]
+	}
+	if i := strings.LastIndex(s, ":"); i >= 0 && !strings.Contains(s[i+1:], "/") {
+		s, img.Tag = s[:i], s[i+1:]
+	}
+
+	img.Registry = DefaultRegistry
+	if i := strings.Index(s, "/"); i >= 0 {
+		first := s[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			img.Registry, s = first, s[i+1:]
+		}
+	}
+	img.Repository = s
+
+	return img
+}
+
+func (img Image) String() string {
+	s := img.Repository
+	if img.Registry != DefaultRegistry {
+		s = img.Registry + "/" + s
+	}
+	if img.Tag != "" {
+		s += ":" + img.Tag
+	}
+	if img.Digest != "" {
+		s += "@" + img.Digest
+	}
+	return s
+}
+
+// ShortDigest truncate digest to algorithm:12 characters.
+func (img Image) ShortDigest() string {
+	d := img.Digest
+	if i := strings.Index(d, ":"); i >= 0 && len(d) > i+13 {
+		return d[:i+13]
+	}
+	return d
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImage(t *testing.T) {
+	fixtures := []struct {
+		s        string
+		expected Image
+	}{
+		{"nginx", Image{Registry: "docker.io", Repository: "nginx"}},
+		{"nginx:1.21", Image{Registry: "docker.io", Repository: "nginx", Tag: "1.21"}},
+		{"team/app:v1", Image{Registry: "docker.io", Repository: "team/app", Tag: "v1"}},
+		{"localhost:5000/app", Image{Registry: "localhost:5000", Repository: "app"}},
+		{
+			"gcr.io/team/app:v1@sha256:0123456789abcdef",
+			Image{Registry: "gcr.io", Repository: "team/app", Tag: "v1", Digest: "sha256:0123456789abcdef"},
+		},
+	}
+
+	for _, f := range fixtures {
+		fixture := f
+		t.Run(fixture.s, func(t *testing.T) {
+			actual := ParseImage(fixture.s)
+			require.Equal(t, fixture.expected, actual)
+			require.Equal(t, fixture.s, actual.String())
+		})
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 
 	apps "k8s.io/api/apps/v1"
+	autoscaling "k8s.io/api/autoscaling/v2beta2"
+	batch "k8s.io/api/batch/v1"
 	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 func PodTemplateAccessor(obj interface{}) (core.PodSpec, error) {
@@ -13,6 +16,12 @@ func PodTemplateAccessor(obj interface{}) (core.PodSpec, error) {
 		return v.Spec.Template.Spec, nil
 	case *apps.StatefulSet:
 		return v.Spec.Template.Spec, nil
+	case *apps.DaemonSet:
+		return v.Spec.Template.Spec, nil
+	case *batch.CronJob:
+		return v.Spec.JobTemplate.Spec.Template.Spec, nil
+	case *batch.Job:
+		return v.Spec.Template.Spec, nil
 	}
 	return core.PodSpec{}, fmt.Errorf("unknown type: %T", obj)
 }
@@ -30,13 +39,29 @@ func ReplicasAccessor(obj interface{}) (int32, error) {
 }
 
 func KindAccessor(obj interface{}) string {
-	switch obj.(type) {
+	switch v := obj.(type) {
 	case *apps.Deployment:
 		return "Deployment"
 	case *apps.ReplicaSet:
 		return "ReplicaSet"
 	case *apps.StatefulSet:
 		return "StatefulSet"
+	case *apps.DaemonSet:
+		return "DaemonSet"
+	case *batch.CronJob:
+		return "CronJob"
+	case *batch.Job:
+		return "Job"
+	case *autoscaling.HorizontalPodAutoscaler:
+		return "HorizontalPodAutoscaler"
+	case *core.ConfigMap:
+		return "ConfigMap"
+	case *core.Secret:
+		return "Secret"
+	case *core.Node:
+		return "Node"
+	case *unstructured.Unstructured:
+		return v.GetKind()
 	}
 	return ""
 }